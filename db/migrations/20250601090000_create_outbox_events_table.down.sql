@@ -0,0 +1,5 @@
+-- Migration: create_outbox_events_table (DOWN)
+-- Version: 20250601090000
+-- Created at: 2025-06-01 09:00:00
+
+DROP TABLE IF EXISTS `outbox_events`;
@@ -0,0 +1,20 @@
+-- Migration: create_outbox_events_table (UP)
+-- Version: 20250601090000
+-- Created at: 2025-06-01 09:00:00
+
+CREATE TABLE IF NOT EXISTS `outbox_events` (
+    `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `topic` varchar(255) NOT NULL COMMENT '事件主题',
+    `event_key` varchar(255) NOT NULL DEFAULT '' COMMENT '分区键，如聚合根ID',
+    `payload` longblob NOT NULL COMMENT '事件内容',
+    `headers` json DEFAULT NULL COMMENT '事件头，包含链路追踪上下文',
+    `status` tinyint(1) NOT NULL DEFAULT '0' COMMENT '状态: 0-待投递, 1-已投递, 2-死信',
+    `attempts` int(11) NOT NULL DEFAULT '0' COMMENT '投递尝试次数',
+    `last_error` varchar(1024) NOT NULL DEFAULT '' COMMENT '最后一次投递错误',
+    `next_attempt_at` datetime(3) NOT NULL COMMENT '下次投递时间',
+    `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+    `published_at` datetime(3) NULL DEFAULT NULL COMMENT '投递成功时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_status_next_attempt_at` (`status`, `next_attempt_at`),
+    KEY `idx_status_created_at` (`status`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='事务性发件箱';
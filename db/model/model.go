@@ -14,10 +14,15 @@ type BaseModel struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 
+// GetID 返回主键，嵌入 BaseModel 的模型因此满足 repository.Model 约束
+func (m BaseModel) GetID() uint {
+	return m.ID
+}
+
 // User 用户模型
 type User struct {
 	BaseModel
-	Username     string `gorm:"uniqueIndex;size:50;not null" json:"username" validate:"required,min=3,max=50"`
+	Username     string `gorm:"uniqueIndex;size:50;not null" json:"username" validate:"required,min=3,max=50,username"`
 	Email        string `gorm:"uniqueIndex;size:100;not null" json:"email" validate:"required,email"`
 	PasswordHash string `gorm:"size:255;not null" json:"-"`
 	Status       int    `gorm:"default:1;not null" json:"status"` // 1-正常, 0-禁用
@@ -0,0 +1,402 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/health"
+)
+
+// AppConfig 应用生命周期配置
+type AppConfig struct {
+	DrainPeriod     time.Duration // 排空等待时间：就绪探针失败后等待负载均衡摘除流量，默认 5s
+	ShutdownTimeout time.Duration // 关闭总超时时间（包含排空时间），默认 10s
+	StartTimeout    time.Duration // 组件单次启动超时时间，默认 30s
+	Retry           RetryPolicy   // 组件启动失败时的默认重试策略
+}
+
+// App 应用生命周期管理器
+// 按依赖关系分层并行启动组件，监听退出信号，并按 排空 -> 逆依赖顺序停止组件 的顺序优雅关闭
+type App struct {
+	mu            sync.Mutex
+	registrations []*registration
+	names         map[string]*registration
+	started       []*registration // 按启动完成顺序记录，用于逆序停止
+	levels        [][]*registration
+	health        *health.Registry
+	config        *AppConfig
+	logger        *zhlog.Helper
+}
+
+// New 创建应用生命周期管理器，healthRegistry 可为 nil
+func New(config *AppConfig, healthRegistry *health.Registry, logger *zhlog.Helper) *App {
+	if config.DrainPeriod < 0 {
+		config.DrainPeriod = 0
+	}
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = 10 * time.Second
+	}
+	if config.StartTimeout <= 0 {
+		config.StartTimeout = 30 * time.Second
+	}
+	if config.Retry.MaxAttempts <= 0 {
+		config.Retry.MaxAttempts = 5
+	}
+	if config.Retry.InitialBackoff <= 0 {
+		config.Retry.InitialBackoff = 500 * time.Millisecond
+	}
+	if config.Retry.MaxBackoff <= 0 {
+		config.Retry.MaxBackoff = 10 * time.Second
+	}
+	if config.Retry.Multiplier < 1 {
+		config.Retry.Multiplier = 2
+	}
+
+	return &App{
+		names:  make(map[string]*registration),
+		health: healthRegistry,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Register 注册组件，组件名称重复时返回错误
+func (a *App) Register(component Component, opts ...Option) error {
+	if component == nil || isNil(component) {
+		return errors.New("app: component is nil")
+	}
+
+	r := &registration{component: component}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	name := component.Name()
+	if _, ok := a.names[name]; ok {
+		return fmt.Errorf("app: component %q already registered", name)
+	}
+	a.names[name] = r
+	a.registrations = append(a.registrations, r)
+	return nil
+}
+
+// MustRegister 注册组件，失败时 panic
+func (a *App) MustRegister(component Component, opts ...Option) {
+	if err := a.Register(component, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// AddServer 添加HTTP服务器，服务器依赖此前注册的所有组件
+func (a *App) AddServer(name string, srv *http.Server) {
+	a.MustRegister(NewHTTPServer(name, srv), DependsOn(a.componentNames()...))
+}
+
+// AddCloser 添加关闭时需要释放的资源，依赖此前注册的所有组件，因此会先于它们被释放
+func (a *App) AddCloser(name string, fn func(ctx context.Context) error) {
+	a.MustRegister(&ComponentFunc{ComponentName: name, StopFunc: fn}, DependsOn(a.componentNames()...))
+}
+
+// componentNames 返回已注册的组件名称
+func (a *App) componentNames() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	names := make([]string, 0, len(a.registrations))
+	for _, r := range a.registrations {
+		names = append(names, r.component.Name())
+	}
+	return names
+}
+
+// Run 启动所有组件并阻塞，直到收到 SIGINT/SIGTERM、ctx 被取消或组件报告致命错误，随后执行优雅关闭
+func (a *App) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := a.Start(ctx); err != nil {
+		return err
+	}
+
+	// 汇总组件运行期间的致命错误
+	errCh := make(chan error, len(a.started))
+	for _, r := range a.started {
+		if f, ok := r.component.(Failer); ok {
+			go func(ch <-chan error) {
+				if err, ok := <-ch; ok {
+					errCh <- err
+				}
+			}(f.Err())
+		}
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		a.logger.Info("收到退出信号，开始优雅关闭")
+	case runErr = <-errCh:
+		a.logger.Error("组件异常退出，开始关闭", "error", runErr)
+	}
+
+	// 恢复默认信号处理，再次收到信号时强制退出
+	stop()
+
+	return errors.Join(runErr, a.Shutdown(context.Background()))
+}
+
+// Start 按依赖关系分层启动组件，同一层内的组件并行启动
+// 任一组件启动失败时，已启动的组件会被逆序停止
+func (a *App) Start(ctx context.Context) error {
+	levels, err := a.resolve()
+	if err != nil {
+		return err
+	}
+	a.levels = levels
+
+	for _, level := range levels {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var errs []error
+
+		for _, r := range level {
+			wg.Add(1)
+			go func(r *registration) {
+				defer wg.Done()
+				err := a.startComponent(ctx, r)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					errs = append(errs, err)
+					return
+				}
+				a.started = append(a.started, r)
+			}(r)
+		}
+		wg.Wait()
+
+		if len(errs) > 0 {
+			startErr := errors.Join(errs...)
+			a.logger.Error("组件启动失败，停止已启动的组件", "error", startErr)
+			stopCtx, cancel := context.WithTimeout(context.Background(), a.config.ShutdownTimeout)
+			defer cancel()
+			return errors.Join(startErr, a.stopAll(stopCtx))
+		}
+	}
+
+	a.logger.Info("应用已启动", "components", len(a.started))
+	return nil
+}
+
+// startComponent 在超时时间内启动单个组件，失败时按退避策略重试
+func (a *App) startComponent(ctx context.Context, r *registration) error {
+	name := r.component.Name()
+	timeout := r.startTimeout
+	if timeout <= 0 {
+		timeout = a.config.StartTimeout
+	}
+	policy := a.config.Retry
+	if r.retry != nil {
+		policy = *r.retry
+	}
+
+	var err error
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		startCtx, cancel := context.WithTimeout(ctx, timeout)
+		start := time.Now()
+		err = r.component.Start(startCtx)
+		cancel()
+
+		if err == nil {
+			a.logger.Info("组件已启动", "component", name, "elapsed", time.Since(start))
+			return nil
+		}
+
+		if attempt == policy.MaxAttempts {
+			break
+		}
+
+		backoff := policy.backoff(attempt)
+		a.logger.Warn("组件启动失败，稍后重试", "component", name, "attempt", attempt, "backoff", backoff, "error", err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return fmt.Errorf("start %s: %w", name, ctx.Err())
+		}
+	}
+
+	return fmt.Errorf("start %s after %d attempts: %w", name, policy.MaxAttempts, err)
+}
+
+// Shutdown 优雅关闭：标记未就绪并等待排空，再按依赖的相反顺序停止组件
+// 整个过程受 ShutdownTimeout 限制，超时未完成的组件会被记录
+func (a *App) Shutdown(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, a.config.ShutdownTimeout)
+	defer cancel()
+
+	start := time.Now()
+
+	// 排空：就绪探针返回失败，等待负载均衡摘除本实例
+	if a.health != nil {
+		a.health.SetDraining(true)
+	}
+	if a.config.DrainPeriod > 0 {
+		a.logger.Info("进入排空阶段", "drain_period", a.config.DrainPeriod)
+		select {
+		case <-time.After(a.config.DrainPeriod):
+		case <-ctx.Done():
+		}
+	}
+
+	err := a.stopAll(ctx)
+	a.logger.Info("应用已关闭", "elapsed", time.Since(start))
+	return err
+}
+
+// stopAll 按启动层级的相反顺序停止已启动的组件，同一层内并行停止
+func (a *App) stopAll(ctx context.Context) error {
+	started := make(map[*registration]bool, len(a.started))
+	for _, r := range a.started {
+		started[r] = true
+	}
+
+	var errs []error
+	var unfinished []string
+	for i := len(a.levels) - 1; i >= 0; i-- {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+
+		for _, r := range a.levels[i] {
+			if !started[r] {
+				continue
+			}
+
+			wg.Add(1)
+			go func(r *registration) {
+				defer wg.Done()
+				err := a.stopComponent(ctx, r)
+				if err == nil {
+					return
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, fmt.Errorf("stop %s: %w", r.component.Name(), err))
+				if errors.Is(err, context.DeadlineExceeded) {
+					unfinished = append(unfinished, r.component.Name())
+				}
+			}(r)
+		}
+		wg.Wait()
+	}
+	a.started = nil
+
+	if len(unfinished) > 0 {
+		a.logger.Error("以下组件未能在关闭超时内停止", "components", unfinished, "timeout", a.config.ShutdownTimeout)
+	}
+	return errors.Join(errs...)
+}
+
+// stopComponent 在剩余超时时间内停止单个组件
+func (a *App) stopComponent(ctx context.Context, r *registration) error {
+	name := r.component.Name()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.component.Stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			a.logger.Error("组件停止失败", "component", name, "error", err)
+			return err
+		}
+		a.logger.Info("组件已停止", "component", name)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// resolve 根据依赖关系将组件分层（拓扑排序），检测未知依赖和循环依赖
+func (a *App) resolve() ([][]*registration, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	indegree := make(map[*registration]int, len(a.registrations))
+	dependents := make(map[*registration][]*registration)
+	for _, r := range a.registrations {
+		indegree[r] = 0
+	}
+	for _, r := range a.registrations {
+		for _, dep := range r.deps {
+			d, ok := a.names[dep]
+			if !ok {
+				return nil, fmt.Errorf("app: component %q depends on unknown component %q", r.component.Name(), dep)
+			}
+			indegree[r]++
+			dependents[d] = append(dependents[d], r)
+		}
+	}
+
+	var levels [][]*registration
+	var current []*registration
+	for _, r := range a.registrations {
+		if indegree[r] == 0 {
+			current = append(current, r)
+		}
+	}
+
+	resolved := 0
+	for len(current) > 0 {
+		levels = append(levels, current)
+		resolved += len(current)
+
+		var next []*registration
+		for _, r := range current {
+			for _, dependent := range dependents[r] {
+				indegree[dependent]--
+				if indegree[dependent] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		current = next
+	}
+
+	if resolved != len(a.registrations) {
+		var cyclic []string
+		for _, r := range a.registrations {
+			if indegree[r] > 0 {
+				cyclic = append(cyclic, r.component.Name())
+			}
+		}
+		return nil, fmt.Errorf("app: dependency cycle detected among components %v", cyclic)
+	}
+
+	return levels, nil
+}
+
+// isNil 判断接口中是否为 nil 指针，避免注册 (*T)(nil) 形式的组件
+func isNil(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
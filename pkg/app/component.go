@@ -0,0 +1,221 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Component 应用组件，如数据库连接、缓存客户端、HTTP服务器等
+type Component interface {
+	// Name 组件名称，在同一个应用内唯一，用于声明依赖关系
+	Name() string
+	// Start 启动组件，返回错误时应用会按重试策略重试
+	Start(ctx context.Context) error
+	// Stop 停止组件并释放资源
+	Stop(ctx context.Context) error
+}
+
+// Failer 可选接口：组件运行期间出现致命错误时通过该通道通知应用退出
+type Failer interface {
+	Err() <-chan error
+}
+
+// RetryPolicy 组件启动失败时的重试策略（指数退避）
+type RetryPolicy struct {
+	MaxAttempts    int           // 最大尝试次数，1 表示不重试
+	InitialBackoff time.Duration // 首次重试等待时间
+	MaxBackoff     time.Duration // 最大等待时间
+	Multiplier     float64       // 退避倍数
+}
+
+// backoff 返回第 attempt 次失败后的等待时间
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		d *= p.Multiplier
+		if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+	return time.Duration(d)
+}
+
+// registration 已注册的组件
+type registration struct {
+	component    Component
+	deps         []string
+	startTimeout time.Duration
+	retry        *RetryPolicy
+}
+
+// Option 组件注册选项
+type Option func(*registration)
+
+// DependsOn 声明组件依赖，依赖的组件会先于本组件启动、晚于本组件停止
+func DependsOn(names ...string) Option {
+	return func(r *registration) {
+		r.deps = append(r.deps, names...)
+	}
+}
+
+// WithStartTimeout 设置组件单次启动的超时时间
+func WithStartTimeout(timeout time.Duration) Option {
+	return func(r *registration) {
+		r.startTimeout = timeout
+	}
+}
+
+// WithRetry 设置组件启动失败时的重试策略
+func WithRetry(policy RetryPolicy) Option {
+	return func(r *registration) {
+		r.retry = &policy
+	}
+}
+
+// ComponentFunc 由函数构成的组件，Start/Stop 可为 nil
+type ComponentFunc struct {
+	ComponentName string
+	StartFunc     func(ctx context.Context) error
+	StopFunc      func(ctx context.Context) error
+}
+
+// Name 组件名称
+func (c *ComponentFunc) Name() string {
+	return c.ComponentName
+}
+
+// Start 启动组件
+func (c *ComponentFunc) Start(ctx context.Context) error {
+	if c.StartFunc == nil {
+		return nil
+	}
+	return c.StartFunc(ctx)
+}
+
+// Stop 停止组件
+func (c *ComponentFunc) Stop(ctx context.Context) error {
+	if c.StopFunc == nil {
+		return nil
+	}
+	return c.StopFunc(ctx)
+}
+
+// HTTPServer HTTP服务器组件
+// Start 完成端口监听后即返回，Stop 调用 http.Server.Shutdown 等待处理中的请求完成
+type HTTPServer struct {
+	name  string
+	srv   *http.Server
+	errCh chan error
+}
+
+// NewHTTPServer 创建HTTP服务器组件
+func NewHTTPServer(name string, srv *http.Server) *HTTPServer {
+	return &HTTPServer{
+		name:  name,
+		srv:   srv,
+		errCh: make(chan error, 1),
+	}
+}
+
+// Name 组件名称
+func (s *HTTPServer) Name() string {
+	return s.name
+}
+
+// Start 监听端口并在后台处理请求
+func (s *HTTPServer) Start(ctx context.Context) error {
+	var lc net.ListenConfig
+	ln, err := lc.Listen(ctx, "tcp", s.srv.Addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.errCh <- fmt.Errorf("server %s: %w", s.name, err)
+		}
+	}()
+	return nil
+}
+
+// Stop 停止接收新请求并等待处理中的请求完成，超时后强制关闭连接
+func (s *HTTPServer) Stop(ctx context.Context) error {
+	if err := s.srv.Shutdown(ctx); err != nil {
+		_ = s.srv.Close()
+		return err
+	}
+	return nil
+}
+
+// Err 服务器运行期间的致命错误
+func (s *HTTPServer) Err() <-chan error {
+	return s.errCh
+}
+
+// GRPCServer gRPC服务器组件
+// Start 完成端口监听后即返回，Stop 调用 grpc.Server.GracefulStop 等待处理中的调用完成
+type GRPCServer struct {
+	name  string
+	addr  string
+	srv   *grpc.Server
+	errCh chan error
+}
+
+// NewGRPCServer 创建gRPC服务器组件，addr 为监听地址，如 ":9090"
+func NewGRPCServer(name string, srv *grpc.Server, addr string) *GRPCServer {
+	return &GRPCServer{
+		name:  name,
+		addr:  addr,
+		srv:   srv,
+		errCh: make(chan error, 1),
+	}
+}
+
+// Name 组件名称
+func (s *GRPCServer) Name() string {
+	return s.name
+}
+
+// Start 监听端口并在后台处理调用
+func (s *GRPCServer) Start(ctx context.Context) error {
+	var lc net.ListenConfig
+	ln, err := lc.Listen(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			s.errCh <- fmt.Errorf("server %s: %w", s.name, err)
+		}
+	}()
+	return nil
+}
+
+// Stop 停止接收新调用并等待处理中的调用（包括流式调用）完成，超时后强制关闭连接
+func (s *GRPCServer) Stop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.srv.Stop()
+		return ctx.Err()
+	}
+}
+
+// Err 服务器运行期间的致命错误
+func (s *GRPCServer) Err() <-chan error {
+	return s.errCh
+}
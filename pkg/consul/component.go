@@ -0,0 +1,51 @@
+package consul
+
+import (
+	"context"
+
+	"github.com/hashicorp/consul/api"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+)
+
+// Component Consul应用组件
+// 创建时初始化客户端，Start 时检查集群是否可用；客户端基于 HTTP，无需关闭
+type Component struct {
+	client *api.Client
+	logger *zhlog.Helper
+}
+
+// NewComponent 创建Consul组件
+func NewComponent(config *ConsulConfig, logger *zhlog.Helper) (*Component, error) {
+	client, err := api.NewClient(newClientConfig(config))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Component{client: client, logger: logger}, nil
+}
+
+// Name 组件名称
+func (c *Component) Name() string {
+	return "consul"
+}
+
+// Start 检查Consul集群是否可用
+func (c *Component) Start(ctx context.Context) error {
+	if err := HealthCheck(c.client).Checker.Check(ctx); err != nil {
+		return err
+	}
+
+	c.logger.Info("Consul 连接成功")
+	return nil
+}
+
+// Stop 客户端无需关闭
+func (c *Component) Stop(ctx context.Context) error {
+	return nil
+}
+
+// Client 返回Consul客户端
+func (c *Component) Client() *api.Client {
+	return c.client
+}
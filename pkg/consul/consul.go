@@ -1 +1,86 @@
 package consul
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/health"
+)
+
+// ConsulConfig Consul配置
+type ConsulConfig struct {
+	Address    string // Consul 地址，如 127.0.0.1:8500 或 http://consul:8500，为空时使用 CONSUL_HTTP_ADDR 或默认地址
+	Scheme     string // http 或 https，Address 带协议时可不填
+	Datacenter string // 数据中心，为空时使用 agent 所在数据中心
+	Token      string // ACL 令牌
+	WaitTime   int    // 阻塞查询最长等待时间（秒），默认 300
+}
+
+// NewConsul 创建 Consul 客户端并检查集群是否可用，失败时返回错误
+func NewConsul(config *ConsulConfig, logger *zhlog.Helper) (*api.Client, error) {
+	consulClient, err := api.NewClient(newClientConfig(config))
+	if err != nil {
+		logger.Error("Consul 配置无效", "error", err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// api.NewClient 不会访问 agent，通过查询 leader 确认集群可用
+	if err := HealthCheck(consulClient).Checker.Check(ctx); err != nil {
+		logger.Error("Consul 连接失败", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Consul 连接成功")
+
+	return consulClient, nil
+}
+
+// newClientConfig 根据配置创建客户端配置
+func newClientConfig(config *ConsulConfig) *api.Config {
+	clientConfig := api.DefaultConfig()
+	if config.Address != "" {
+		clientConfig.Address = config.Address // Consul 地址
+	}
+	if config.Scheme != "" {
+		clientConfig.Scheme = config.Scheme // 协议
+	}
+	if config.Datacenter != "" {
+		clientConfig.Datacenter = config.Datacenter // 数据中心
+	}
+	if config.Token != "" {
+		clientConfig.Token = config.Token // ACL 令牌
+	}
+	clientConfig.WaitTime = 5 * time.Minute // 阻塞查询最长等待时间
+	if config.WaitTime > 0 {
+		clientConfig.WaitTime = time.Duration(config.WaitTime) * time.Second
+	}
+	return clientConfig
+}
+
+// HealthCheck 返回Consul健康检查项，集群存在 leader 即视为健康
+// Consul 通常用于服务发现和配置中心，默认作为非关键依赖
+func HealthCheck(client *api.Client) health.Check {
+	return health.Check{
+		Name:     "consul",
+		Timeout:  2 * time.Second,
+		Critical: false,
+		Checker: health.CheckerFunc(func(ctx context.Context) error {
+			leader, err := client.Status().LeaderWithQueryOptions((&api.QueryOptions{}).WithContext(ctx))
+			if err != nil {
+				return err
+			}
+			if leader == "" {
+				return errors.New("consul cluster has no leader")
+			}
+			return nil
+		}),
+	}
+}
@@ -0,0 +1,190 @@
+package consul
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// fakeConsul 模拟 Consul HTTP API 的最小实现，支持 agent 注册、健康查询和 KV 的阻塞查询
+type fakeConsul struct {
+	mu       sync.Mutex
+	index    uint64
+	changed  chan struct{}
+	kv       map[string]*api.KVPair
+	services map[string]*api.AgentServiceRegistration
+	ttl      map[string]int // 键为检查ID，值为上报次数
+}
+
+// newFakeConsul 创建模拟 Consul 服务并返回连接它的客户端
+func newFakeConsul(t *testing.T) (*fakeConsul, *api.Client) {
+	t.Helper()
+	f := &fakeConsul{
+		index:    1,
+		changed:  make(chan struct{}),
+		kv:       make(map[string]*api.KVPair),
+		services: make(map[string]*api.AgentServiceRegistration),
+		ttl:      make(map[string]int),
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	client, err := api.NewClient(newClientConfig(&ConsulConfig{Address: srv.URL}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, client
+}
+
+// bump 推进索引并唤醒阻塞查询，调用方需持有锁
+func (f *fakeConsul) bump() uint64 {
+	f.index++
+	close(f.changed)
+	f.changed = make(chan struct{})
+	return f.index
+}
+
+// wait 阻塞直到索引超过 index 或请求结束，返回时持有锁
+func (f *fakeConsul) wait(r *http.Request) {
+	index, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+	f.mu.Lock()
+	for index > 0 && f.index <= index {
+		ch := f.changed
+		f.mu.Unlock()
+		select {
+		case <-ch:
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+		f.mu.Lock()
+		if r.Context().Err() != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	switch {
+	case path == "/v1/status/leader":
+		writeJSON(w, 0, "127.0.0.1:8300")
+
+	case path == "/v1/agent/service/register":
+		var reg api.AgentServiceRegistration
+		if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		f.services[reg.ID] = &reg
+		f.bump()
+		f.mu.Unlock()
+
+	case strings.HasPrefix(path, "/v1/agent/service/deregister/"):
+		f.mu.Lock()
+		delete(f.services, strings.TrimPrefix(path, "/v1/agent/service/deregister/"))
+		f.bump()
+		f.mu.Unlock()
+
+	case strings.HasPrefix(path, "/v1/agent/check/update/"):
+		id := strings.TrimPrefix(path, "/v1/agent/check/update/")
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, ok := f.services[strings.TrimSuffix(strings.TrimPrefix(id, "service:"), ":ttl")]; !ok {
+			http.Error(w, "unknown check", http.StatusNotFound)
+			return
+		}
+		f.ttl[id]++
+
+	case strings.HasPrefix(path, "/v1/health/service/"):
+		name := strings.TrimPrefix(path, "/v1/health/service/")
+		f.wait(r)
+		defer f.mu.Unlock()
+		var entries []*api.ServiceEntry
+		for _, reg := range f.services {
+			if reg.Name != name {
+				continue
+			}
+			entries = append(entries, &api.ServiceEntry{
+				Node: &api.Node{Address: "10.0.0.1"},
+				Service: &api.AgentService{
+					ID:      reg.ID,
+					Service: reg.Name,
+					Address: reg.Address,
+					Port:    reg.Port,
+					Meta:    reg.Meta,
+					Weights: *reg.Weights,
+				},
+			})
+		}
+		writeJSON(w, f.index, entries)
+
+	case strings.HasPrefix(path, "/v1/kv/"):
+		f.serveKV(w, r, strings.TrimPrefix(path, "/v1/kv/"))
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveKV 处理 KV 的读写、CAS 和删除
+func (f *fakeConsul) serveKV(w http.ResponseWriter, r *http.Request, key string) {
+	switch r.Method {
+	case http.MethodGet:
+		f.wait(r)
+		defer f.mu.Unlock()
+		pair, ok := f.kv[key]
+		if !ok {
+			w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, f.index, []*api.KVPair{pair})
+
+	case http.MethodPut:
+		value, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if cas := r.URL.Query().Get("cas"); cas != "" {
+			want, _ := strconv.ParseUint(cas, 10, 64)
+			var current uint64
+			if pair, ok := f.kv[key]; ok {
+				current = pair.ModifyIndex
+			}
+			if current != want {
+				writeJSON(w, 0, false)
+				return
+			}
+		}
+		f.kv[key] = &api.KVPair{Key: key, Value: value, ModifyIndex: f.bump()}
+		writeJSON(w, 0, true)
+
+	case http.MethodDelete:
+		f.mu.Lock()
+		delete(f.kv, key)
+		f.bump()
+		f.mu.Unlock()
+		writeJSON(w, 0, true)
+	}
+}
+
+// writeJSON 写入 JSON 响应，index 大于 0 时设置 X-Consul-Index
+func writeJSON(w http.ResponseWriter, index uint64, v interface{}) {
+	if index > 0 {
+		w.Header().Set("X-Consul-Index", strconv.FormatUint(index, 10))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
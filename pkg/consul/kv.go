@@ -0,0 +1,117 @@
+package consul
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+
+	"go-template/pkg/kv"
+)
+
+// KVStore 基于 Consul KV 的键值存储，实现 kv.Store
+// Consul 的键不以 / 开头，etcd 风格的键（如 /config/app）会去掉开头的 /
+type KVStore struct {
+	client *api.Client
+}
+
+// NewKVStore 创建 Consul 键值存储
+func NewKVStore(client *api.Client) *KVStore {
+	return &KVStore{client: client}
+}
+
+// Get 读取键值，键不存在时返回 kv.ErrNotFound
+func (s *KVStore) Get(ctx context.Context, key string) (*kv.Entry, error) {
+	pair, _, err := s.client.KV().Get(consulKey(key), (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if pair == nil {
+		return nil, kv.ErrNotFound
+	}
+	return &kv.Entry{Key: key, Value: pair.Value, Revision: int64(pair.ModifyIndex)}, nil
+}
+
+// Put 写入键值
+func (s *KVStore) Put(ctx context.Context, key string, value []byte) error {
+	_, err := s.client.KV().Put(&api.KVPair{Key: consulKey(key), Value: value}, (&api.WriteOptions{}).WithContext(ctx))
+	return err
+}
+
+// CompareAndSwap 仅当键的 ModifyIndex 等于 revision 时写入，revision 为 0 表示仅当键不存在时写入
+func (s *KVStore) CompareAndSwap(ctx context.Context, key string, value []byte, revision int64) (bool, error) {
+	ok, _, err := s.client.KV().CAS(&api.KVPair{
+		Key:         consulKey(key),
+		Value:       value,
+		ModifyIndex: uint64(revision),
+	}, (&api.WriteOptions{}).WithContext(ctx))
+	return ok, err
+}
+
+// Delete 删除键
+func (s *KVStore) Delete(ctx context.Context, key string) error {
+	_, err := s.client.KV().Delete(consulKey(key), (&api.WriteOptions{}).WithContext(ctx))
+	return err
+}
+
+// Watch 通过阻塞查询监听键的变化
+func (s *KVStore) Watch(ctx context.Context, key string) (kv.Watcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &kvWatcher{client: s.client, key: key, ctx: ctx, cancel: cancel}, nil
+}
+
+// kvWatcher 基于阻塞查询的键监听器
+type kvWatcher struct {
+	client *api.Client
+	key    string
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	index   uint64
+	started bool
+	last    *kv.Entry
+}
+
+// Next 首次调用立即返回当前值，之后阻塞直到键发生变化
+func (w *kvWatcher) Next() (*kv.Entry, error) {
+	for {
+		q := (&api.QueryOptions{WaitIndex: w.index}).WithContext(w.ctx)
+		pair, meta, err := w.client.KV().Get(consulKey(w.key), q)
+		if err != nil {
+			if w.ctx.Err() != nil {
+				return nil, w.ctx.Err()
+			}
+			return nil, err
+		}
+
+		// 索引回退（如 Consul 快照恢复）时从头开始阻塞查询
+		if meta.LastIndex < w.index {
+			w.index = 0
+		} else {
+			w.index = meta.LastIndex
+		}
+
+		entry := &kv.Entry{Key: w.key}
+		if pair != nil {
+			entry.Value = pair.Value
+			entry.Revision = int64(pair.ModifyIndex)
+		}
+		if !w.started || entry.Revision != w.last.Revision || !bytes.Equal(entry.Value, w.last.Value) {
+			w.started = true
+			w.last = entry
+			return entry, nil
+		}
+	}
+}
+
+// Stop 停止监听
+func (w *kvWatcher) Stop() error {
+	w.cancel()
+	return nil
+}
+
+// consulKey 去掉键开头的 /
+func consulKey(key string) string {
+	return strings.TrimPrefix(key, "/")
+}
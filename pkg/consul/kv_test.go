@@ -0,0 +1,115 @@
+package consul
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-template/pkg/kv"
+)
+
+func TestKVStore(t *testing.T) {
+	_, client := newFakeConsul(t)
+	s := NewKVStore(client)
+	ctx := context.Background()
+
+	if _, err := s.Get(ctx, "/config/app"); !errors.Is(err, kv.ErrNotFound) {
+		t.Fatalf("Get missing key: err = %v, want ErrNotFound", err)
+	}
+
+	// revision 为 0 时仅当键不存在时写入
+	ok, err := s.CompareAndSwap(ctx, "/config/app", []byte("v1"), 0)
+	if err != nil || !ok {
+		t.Fatalf("CompareAndSwap create: ok = %v, err = %v", ok, err)
+	}
+	ok, err = s.CompareAndSwap(ctx, "/config/app", []byte("v2"), 0)
+	if err != nil || ok {
+		t.Fatalf("CompareAndSwap create existing: ok = %v, err = %v", ok, err)
+	}
+
+	entry, err := s.Get(ctx, "/config/app")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if entry.Key != "/config/app" || string(entry.Value) != "v1" || entry.Revision == 0 {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+
+	ok, err = s.CompareAndSwap(ctx, "/config/app", []byte("v2"), entry.Revision+1)
+	if err != nil || ok {
+		t.Fatalf("CompareAndSwap stale revision: ok = %v, err = %v", ok, err)
+	}
+	ok, err = s.CompareAndSwap(ctx, "/config/app", []byte("v2"), entry.Revision)
+	if err != nil || !ok {
+		t.Fatalf("CompareAndSwap: ok = %v, err = %v", ok, err)
+	}
+
+	if err := s.Put(ctx, "config/app", []byte("v3")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	entry, err = s.Get(ctx, "/config/app")
+	if err != nil || string(entry.Value) != "v3" {
+		t.Fatalf("Get after Put: entry = %+v, err = %v", entry, err)
+	}
+
+	if err := s.Delete(ctx, "/config/app"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get(ctx, "/config/app"); !errors.Is(err, kv.ErrNotFound) {
+		t.Fatalf("Get after Delete: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestKVWatcher(t *testing.T) {
+	_, client := newFakeConsul(t)
+	s := NewKVStore(client)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	w, err := s.Watch(ctx, "/config/flag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// 首次调用立即返回，键不存在时 Value 为 nil
+	entry, err := w.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if entry.Value != nil {
+		t.Fatalf("initial value = %q, want nil", entry.Value)
+	}
+
+	// 其他键的变化不触发返回
+	if err := s.Put(ctx, "/config/other", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(ctx, "/config/flag", []byte("on")); err != nil {
+		t.Fatal(err)
+	}
+	entry, err = w.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if string(entry.Value) != "on" || entry.Revision == 0 {
+		t.Fatalf("entry after Put = %+v", entry)
+	}
+
+	if err := s.Delete(ctx, "/config/flag"); err != nil {
+		t.Fatal(err)
+	}
+	entry, err = w.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if entry.Value != nil {
+		t.Fatalf("value after Delete = %q, want nil", entry.Value)
+	}
+
+	_ = w.Stop()
+	if _, err := w.Next(); err == nil {
+		t.Fatal("Next after Stop: want error")
+	}
+}
@@ -0,0 +1,357 @@
+package consul
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/discovery"
+)
+
+// 实例元数据中的保留键，其余元数据原样保存在 Consul 服务的 Meta 中
+const (
+	metaVersion    = "version"
+	metaZone       = "zone"
+	metaPortPrefix = "port-"
+)
+
+// RegistryConfig Consul 注册中心配置
+type RegistryConfig struct {
+	TTL             time.Duration // TTL 健康检查时间，由实例在后台定期上报，默认 15s；为负数时不注册 TTL 检查
+	HealthPath      string        // HTTP 健康检查路径，如 /readyz，为空时不注册 HTTP 检查
+	HealthPort      string        // HTTP 健康检查使用的端口名称，默认 http
+	CheckInterval   time.Duration // HTTP 健康检查间隔，默认 10s
+	CheckTimeout    time.Duration // HTTP 健康检查超时时间，默认 5s
+	DeregisterAfter time.Duration // 健康检查持续失败多久后自动注销实例，默认 1m
+	Timeout         time.Duration // 单次请求超时时间（不含阻塞查询），默认 5s
+}
+
+// setDefaults 填充默认配置
+func (c *RegistryConfig) setDefaults() {
+	if c.TTL == 0 {
+		c.TTL = 15 * time.Second
+	}
+	if c.HealthPort == "" {
+		c.HealthPort = "http"
+	}
+	if c.CheckInterval <= 0 {
+		c.CheckInterval = 10 * time.Second
+	}
+	if c.CheckTimeout <= 0 {
+		c.CheckTimeout = 5 * time.Second
+	}
+	if c.DeregisterAfter <= 0 {
+		c.DeregisterAfter = time.Minute
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * time.Second
+	}
+}
+
+// Registry 基于 Consul agent 的注册中心，同时实现 discovery.Registry 和 discovery.Discovery
+type Registry struct {
+	client *api.Client
+	config *RegistryConfig
+	logger *zhlog.Helper
+
+	mu         sync.Mutex
+	heartbeats map[string]*heartbeat // 键为实例ID
+}
+
+// heartbeat TTL 检查上报
+type heartbeat struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRegistry 创建 Consul 注册中心
+func NewRegistry(client *api.Client, config *RegistryConfig, logger *zhlog.Helper) *Registry {
+	config.setDefaults()
+	return &Registry{client: client, config: config, logger: logger, heartbeats: make(map[string]*heartbeat)}
+}
+
+// Register 向本地 agent 注册实例及健康检查；配置 TTL 检查时在后台定期上报，agent 丢失注册信息时自动重新注册
+func (r *Registry) Register(ctx context.Context, instance *discovery.Instance) error {
+	if instance.Name == "" {
+		return errors.New("consul: instance name is empty")
+	}
+	registration, err := r.registration(instance)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.heartbeats[registration.ID]; ok {
+		return fmt.Errorf("consul: instance %s already registered", registration.ID)
+	}
+	if err := r.register(ctx, registration); err != nil {
+		return err
+	}
+
+	if r.config.TTL > 0 {
+		loopCtx, cancel := context.WithCancel(context.Background())
+		hb := &heartbeat{cancel: cancel, done: make(chan struct{})}
+		r.heartbeats[registration.ID] = hb
+		go r.heartbeat(loopCtx, hb, registration)
+	} else {
+		r.heartbeats[registration.ID] = &heartbeat{cancel: func() {}, done: closedChan()}
+	}
+	return nil
+}
+
+// Deregister 停止上报并从 agent 注销实例
+func (r *Registry) Deregister(ctx context.Context, instance *discovery.Instance) error {
+	r.mu.Lock()
+	hb, ok := r.heartbeats[instance.ID]
+	delete(r.heartbeats, instance.ID)
+	r.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	hb.cancel()
+	<-hb.done
+
+	ctx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+	return r.client.Agent().ServiceDeregisterOpts(instance.ID, (&api.QueryOptions{}).WithContext(ctx))
+}
+
+// registration 将实例转换为 Consul 服务注册信息
+func (r *Registry) registration(instance *discovery.Instance) (*api.AgentServiceRegistration, error) {
+	id := instance.ID
+	if id == "" {
+		return nil, errors.New("consul: instance id is empty")
+	}
+	weight := instance.Weight
+	if weight <= 0 {
+		weight = discovery.DefaultWeight
+	}
+
+	meta := make(map[string]string, len(instance.Metadata)+len(instance.Ports)+2)
+	for k, v := range instance.Metadata {
+		meta[k] = v
+	}
+	if instance.Version != "" {
+		meta[metaVersion] = instance.Version
+	}
+	if instance.Zone != "" {
+		meta[metaZone] = instance.Zone
+	}
+	for name, port := range instance.Ports {
+		meta[metaPortPrefix+name] = strconv.Itoa(port)
+	}
+
+	registration := &api.AgentServiceRegistration{
+		ID:      id,
+		Name:    instance.Name,
+		Address: instance.Address,
+		Port:    instance.Ports[r.config.HealthPort],
+		Meta:    meta,
+		Weights: &api.AgentWeights{Passing: weight, Warning: 1},
+	}
+	if instance.Version != "" {
+		registration.Tags = []string{instance.Version}
+	}
+
+	deregisterAfter := r.config.DeregisterAfter.String()
+	if r.config.TTL > 0 {
+		registration.Checks = append(registration.Checks, &api.AgentServiceCheck{
+			CheckID:                        ttlCheckID(id),
+			Name:                           "service ttl",
+			TTL:                            r.config.TTL.String(),
+			DeregisterCriticalServiceAfter: deregisterAfter,
+		})
+	}
+	if r.config.HealthPath != "" {
+		endpoint, ok := instance.Endpoint(r.config.HealthPort)
+		if !ok {
+			return nil, fmt.Errorf("consul: instance %s has no %s port for http check", id, r.config.HealthPort)
+		}
+		registration.Checks = append(registration.Checks, &api.AgentServiceCheck{
+			CheckID:                        "service:" + id + ":http",
+			Name:                           "service http",
+			HTTP:                           "http://" + endpoint + r.config.HealthPath,
+			Interval:                       r.config.CheckInterval.String(),
+			Timeout:                        r.config.CheckTimeout.String(),
+			DeregisterCriticalServiceAfter: deregisterAfter,
+		})
+	}
+	return registration, nil
+}
+
+// register 注册服务，TTL 检查立即标记为通过
+func (r *Registry) register(ctx context.Context, registration *api.AgentServiceRegistration) error {
+	ctx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+
+	opts := api.ServiceRegisterOpts{ReplaceExistingChecks: true}.WithContext(ctx)
+	if err := r.client.Agent().ServiceRegisterOpts(registration, opts); err != nil {
+		return err
+	}
+	if r.config.TTL > 0 {
+		return r.pass(ctx, registration.ID)
+	}
+	return nil
+}
+
+// pass 上报 TTL 检查通过
+func (r *Registry) pass(ctx context.Context, id string) error {
+	return r.client.Agent().UpdateTTLOpts(ttlCheckID(id), "", api.HealthPassing, (&api.QueryOptions{}).WithContext(ctx))
+}
+
+// heartbeat 每 TTL/3 上报一次检查通过；检查不存在（agent 重启丢失注册信息）时重新注册
+func (r *Registry) heartbeat(ctx context.Context, hb *heartbeat, registration *api.AgentServiceRegistration) {
+	defer close(hb.done)
+
+	ticker := time.NewTicker(r.config.TTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		reqCtx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+		err := r.pass(reqCtx, registration.ID)
+		var statusErr api.StatusError
+		if errors.As(err, &statusErr) && statusErr.Code == http.StatusNotFound {
+			r.logger.Warn("Consul 中的实例已丢失，重新注册", "id", registration.ID)
+			err = r.register(reqCtx, registration)
+		}
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			r.logger.Warn("上报 Consul 健康检查失败", "id", registration.ID, "error", err)
+		}
+	}
+}
+
+// GetService 返回服务当前通过健康检查的实例
+func (r *Registry) GetService(ctx context.Context, name string) ([]*discovery.Instance, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+
+	entries, _, err := r.client.Health().Service(name, "", true, (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return toInstances(entries), nil
+}
+
+// Watch 通过阻塞查询监听服务实例变化
+func (r *Registry) Watch(ctx context.Context, name string) (discovery.Watcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &catalogWatcher{client: r.client, name: name, ctx: ctx, cancel: cancel}, nil
+}
+
+// catalogWatcher 基于阻塞查询的服务实例监听器
+type catalogWatcher struct {
+	client *api.Client
+	name   string
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	index     uint64
+	started   bool
+	instances []*discovery.Instance
+}
+
+// Next 首次调用立即返回当前实例，之后阻塞直到实例列表发生变化
+func (w *catalogWatcher) Next() ([]*discovery.Instance, error) {
+	for {
+		q := (&api.QueryOptions{WaitIndex: w.index}).WithContext(w.ctx)
+		entries, meta, err := w.client.Health().Service(w.name, "", true, q)
+		if err != nil {
+			if w.ctx.Err() != nil {
+				return nil, w.ctx.Err()
+			}
+			return nil, err
+		}
+
+		// 索引回退（如 Consul 快照恢复）时从头开始阻塞查询
+		if meta.LastIndex < w.index {
+			w.index = 0
+		} else {
+			w.index = meta.LastIndex
+		}
+
+		instances := toInstances(entries)
+		if !w.started || !reflect.DeepEqual(instances, w.instances) {
+			w.started = true
+			w.instances = instances
+			return instances, nil
+		}
+	}
+}
+
+// Stop 停止监听
+func (w *catalogWatcher) Stop() error {
+	w.cancel()
+	return nil
+}
+
+// toInstances 将健康检查结果转换为实例列表，按实例ID排序
+func toInstances(entries []*api.ServiceEntry) []*discovery.Instance {
+	instances := make([]*discovery.Instance, 0, len(entries))
+	for _, entry := range entries {
+		svc := entry.Service
+		ins := &discovery.Instance{
+			ID:      svc.ID,
+			Name:    svc.Service,
+			Address: svc.Address,
+			Weight:  svc.Weights.Passing,
+			Ports:   make(map[string]int),
+		}
+		if ins.Address == "" && entry.Node != nil {
+			ins.Address = entry.Node.Address
+		}
+		for k, v := range svc.Meta {
+			switch {
+			case k == metaVersion:
+				ins.Version = v
+			case k == metaZone:
+				ins.Zone = v
+			case strings.HasPrefix(k, metaPortPrefix):
+				if port, err := strconv.Atoi(v); err == nil {
+					ins.Ports[strings.TrimPrefix(k, metaPortPrefix)] = port
+				}
+			default:
+				if ins.Metadata == nil {
+					ins.Metadata = make(map[string]string)
+				}
+				ins.Metadata[k] = v
+			}
+		}
+		if len(ins.Ports) == 0 && svc.Port > 0 {
+			ins.Ports["http"] = svc.Port
+		}
+		instances = append(instances, ins)
+	}
+	sort.Slice(instances, func(i, j int) bool { return instances[i].ID < instances[j].ID })
+	return instances
+}
+
+// ttlCheckID 实例 TTL 检查的ID
+func ttlCheckID(id string) string {
+	return "service:" + id + ":ttl"
+}
+
+// closedChan 返回已关闭的通道
+func closedChan() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
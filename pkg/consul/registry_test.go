@@ -0,0 +1,151 @@
+package consul
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-template/pkg/discovery"
+)
+
+func TestRegistryRegisterAndGetService(t *testing.T) {
+	f, client := newFakeConsul(t)
+	r := NewRegistry(client, &RegistryConfig{}, nil)
+	ctx := context.Background()
+
+	instance := &discovery.Instance{
+		ID:       "user-1",
+		Name:     "user-service",
+		Address:  "10.0.0.2",
+		Version:  "v1",
+		Zone:     "az1",
+		Ports:    map[string]int{"http": 8080, "grpc": 9090},
+		Metadata: map[string]string{"env": "test"},
+	}
+	if err := r.Register(ctx, instance); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := r.Register(ctx, instance); err == nil {
+		t.Fatal("Register twice: want error")
+	}
+
+	f.mu.Lock()
+	passes := f.ttl[ttlCheckID("user-1")]
+	f.mu.Unlock()
+	if passes != 1 {
+		t.Fatalf("ttl passes = %d, want 1", passes)
+	}
+
+	got, err := r.GetService(ctx, "user-service")
+	if err != nil {
+		t.Fatalf("GetService: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("GetService returned %d instances, want 1", len(got))
+	}
+	ins := got[0]
+	if ins.ID != "user-1" || ins.Address != "10.0.0.2" || ins.Version != "v1" || ins.Zone != "az1" {
+		t.Fatalf("unexpected instance: %+v", ins)
+	}
+	if ins.Ports["http"] != 8080 || ins.Ports["grpc"] != 9090 {
+		t.Fatalf("ports = %v", ins.Ports)
+	}
+	if ins.Metadata["env"] != "test" || ins.Weight != discovery.DefaultWeight {
+		t.Fatalf("metadata = %v, weight = %d", ins.Metadata, ins.Weight)
+	}
+
+	if err := r.Deregister(ctx, instance); err != nil {
+		t.Fatalf("Deregister: %v", err)
+	}
+	got, err = r.GetService(ctx, "user-service")
+	if err != nil {
+		t.Fatalf("GetService: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("GetService after Deregister returned %d instances", len(got))
+	}
+}
+
+func TestRegistryRegisterValidation(t *testing.T) {
+	_, client := newFakeConsul(t)
+	r := NewRegistry(client, &RegistryConfig{HealthPath: "/readyz"}, nil)
+	ctx := context.Background()
+
+	if err := r.Register(ctx, &discovery.Instance{ID: "a"}); err == nil {
+		t.Fatal("empty name: want error")
+	}
+	if err := r.Register(ctx, &discovery.Instance{Name: "svc"}); err == nil {
+		t.Fatal("empty id: want error")
+	}
+	err := r.Register(ctx, &discovery.Instance{ID: "a", Name: "svc", Ports: map[string]int{"grpc": 9090}})
+	if err == nil {
+		t.Fatal("missing health port: want error")
+	}
+}
+
+func TestCatalogWatcher(t *testing.T) {
+	_, client := newFakeConsul(t)
+	r := NewRegistry(client, &RegistryConfig{TTL: -1}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	w, err := r.Watch(ctx, "order-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// 首次调用立即返回当前（空）实例列表
+	got, err := w.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("initial instances = %d, want 0", len(got))
+	}
+
+	// 其他服务的变化不触发返回
+	other := &discovery.Instance{ID: "other-1", Name: "other-service", Address: "10.0.0.9", Ports: map[string]int{"http": 80}}
+	if err := r.Register(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	instance := &discovery.Instance{ID: "order-1", Name: "order-service", Address: "10.0.0.3", Ports: map[string]int{"http": 8080}}
+	if err := r.Register(ctx, instance); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = w.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "order-1" {
+		t.Fatalf("instances after register = %+v", got)
+	}
+
+	if err := r.Deregister(ctx, instance); err != nil {
+		t.Fatal(err)
+	}
+	got, err = w.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("instances after deregister = %d, want 0", len(got))
+	}
+
+	// Stop 后阻塞中的 Next 返回
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.Next()
+		done <- err
+	}()
+	_ = w.Stop()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("Next after Stop: want error")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Next did not return after Stop")
+	}
+}
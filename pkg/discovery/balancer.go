@@ -0,0 +1,181 @@
+package discovery
+
+import (
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// 负载均衡策略名称，用于配置文件
+const (
+	RoundRobin     = "round_robin"
+	Weighted       = "weighted"
+	ConsistentHash = "consistent_hash"
+)
+
+// Balancer 负载均衡器
+type Balancer interface {
+	// Update 更新可用实例列表
+	Update(instances []*Instance)
+	// Pick 选择一个实例，key 仅用于一致性哈希
+	Pick(key string) (*Instance, error)
+}
+
+// NewBalancer 按策略名称创建负载均衡器，策略为空时使用轮询
+func NewBalancer(strategy string) (Balancer, error) {
+	switch strategy {
+	case "", RoundRobin:
+		return NewRoundRobinBalancer(), nil
+	case Weighted:
+		return NewWeightedBalancer(), nil
+	case ConsistentHash:
+		return NewConsistentHashBalancer(0), nil
+	default:
+		return nil, fmt.Errorf("discovery: unknown balancer %q", strategy)
+	}
+}
+
+// RoundRobinBalancer 轮询负载均衡
+type RoundRobinBalancer struct {
+	instances atomic.Pointer[[]*Instance]
+	next      atomic.Uint64
+}
+
+// NewRoundRobinBalancer 创建轮询负载均衡器
+func NewRoundRobinBalancer() *RoundRobinBalancer {
+	return &RoundRobinBalancer{}
+}
+
+// Update 更新可用实例列表
+func (b *RoundRobinBalancer) Update(instances []*Instance) {
+	b.instances.Store(&instances)
+}
+
+// Pick 按顺序选择下一个实例
+func (b *RoundRobinBalancer) Pick(string) (*Instance, error) {
+	p := b.instances.Load()
+	if p == nil || len(*p) == 0 {
+		return nil, ErrNoInstances
+	}
+	list := *p
+	return list[(b.next.Add(1)-1)%uint64(len(list))], nil
+}
+
+// WeightedBalancer 平滑加权轮询负载均衡，按实例权重分配请求且不会连续集中选择同一实例
+type WeightedBalancer struct {
+	mu    sync.Mutex
+	peers []*weightedPeer
+}
+
+// weightedPeer 加权轮询节点
+type weightedPeer struct {
+	instance *Instance
+	weight   int
+	current  int
+}
+
+// NewWeightedBalancer 创建加权轮询负载均衡器
+func NewWeightedBalancer() *WeightedBalancer {
+	return &WeightedBalancer{}
+}
+
+// Update 更新可用实例列表
+func (b *WeightedBalancer) Update(instances []*Instance) {
+	peers := make([]*weightedPeer, 0, len(instances))
+	for _, ins := range instances {
+		peers = append(peers, &weightedPeer{instance: ins, weight: ins.weight()})
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.peers = peers
+}
+
+// Pick 每次为所有节点累加权重，选择当前值最大的节点并减去总权重
+func (b *WeightedBalancer) Pick(string) (*Instance, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.peers) == 0 {
+		return nil, ErrNoInstances
+	}
+	total := 0
+	var best *weightedPeer
+	for _, p := range b.peers {
+		p.current += p.weight
+		total += p.weight
+		if best == nil || p.current > best.current {
+			best = p
+		}
+	}
+	best.current -= total
+	return best.instance, nil
+}
+
+// defaultReplicas 权重为默认值的实例在哈希环上的虚拟节点数
+const defaultReplicas = 160
+
+// ConsistentHashBalancer 一致性哈希负载均衡，相同 key 的请求落到同一实例，实例增减时只影响少量 key
+// 虚拟节点数与实例权重成正比；key 为空时退化为轮询
+type ConsistentHashBalancer struct {
+	replicas int
+	ring     atomic.Pointer[hashRing]
+	fallback RoundRobinBalancer
+}
+
+// hashRing 哈希环
+type hashRing struct {
+	hashes []uint32
+	nodes  map[uint32]*Instance
+}
+
+// NewConsistentHashBalancer 创建一致性哈希负载均衡器，replicas 为默认权重实例的虚拟节点数，默认 160
+func NewConsistentHashBalancer(replicas int) *ConsistentHashBalancer {
+	if replicas <= 0 {
+		replicas = defaultReplicas
+	}
+	return &ConsistentHashBalancer{replicas: replicas}
+}
+
+// Update 更新可用实例列表并重建哈希环
+func (b *ConsistentHashBalancer) Update(instances []*Instance) {
+	ring := &hashRing{nodes: make(map[uint32]*Instance)}
+	for _, ins := range instances {
+		n := b.replicas * ins.weight() / DefaultWeight
+		if n < 1 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			h := crc32.ChecksumIEEE([]byte(ins.ID + "#" + strconv.Itoa(i)))
+			if _, ok := ring.nodes[h]; ok {
+				continue
+			}
+			ring.nodes[h] = ins
+			ring.hashes = append(ring.hashes, h)
+		}
+	}
+	sort.Slice(ring.hashes, func(i, j int) bool { return ring.hashes[i] < ring.hashes[j] })
+
+	b.ring.Store(ring)
+	b.fallback.Update(instances)
+}
+
+// Pick 选择哈希环上 key 之后的第一个节点
+func (b *ConsistentHashBalancer) Pick(key string) (*Instance, error) {
+	if key == "" {
+		return b.fallback.Pick(key)
+	}
+	ring := b.ring.Load()
+	if ring == nil || len(ring.hashes) == 0 {
+		return nil, ErrNoInstances
+	}
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(ring.hashes), func(i int) bool { return ring.hashes[i] >= h })
+	if i == len(ring.hashes) {
+		i = 0
+	}
+	return ring.nodes[ring.hashes[i]], nil
+}
@@ -0,0 +1,116 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+)
+
+// ErrNoInstances 没有可用的服务实例
+var ErrNoInstances = errors.New("discovery: no available instances")
+
+// DefaultWeight 未设置权重时的默认权重
+const DefaultWeight = 100
+
+// Instance 服务实例
+type Instance struct {
+	ID       string            `json:"id"`                 // 实例ID，同一服务内唯一，默认 主机名-进程号
+	Name     string            `json:"name"`               // 服务名称，如 user-service
+	Address  string            `json:"address"`            // 主机地址（IP 或域名）
+	Version  string            `json:"version,omitempty"`  // 服务版本
+	Zone     string            `json:"zone,omitempty"`     // 可用区
+	Weight   int               `json:"weight,omitempty"`   // 权重，默认 100
+	Ports    map[string]int    `json:"ports"`              // 端口，如 {"http": 8080, "grpc": 9090}
+	Metadata map[string]string `json:"metadata,omitempty"` // 其他元数据
+}
+
+// Endpoint 返回指定端口的 host:port 地址
+func (i *Instance) Endpoint(port string) (string, bool) {
+	p, ok := i.Ports[port]
+	if !ok {
+		return "", false
+	}
+	return net.JoinHostPort(i.Address, strconv.Itoa(p)), true
+}
+
+// setDefaults 填充默认值
+func (i *Instance) setDefaults() {
+	if i.ID == "" {
+		host, _ := os.Hostname()
+		i.ID = host + "-" + strconv.Itoa(os.Getpid())
+	}
+	if i.Weight <= 0 {
+		i.Weight = DefaultWeight
+	}
+}
+
+// weight 返回实例权重，未设置时为默认权重
+func (i *Instance) weight() int {
+	if i.Weight <= 0 {
+		return DefaultWeight
+	}
+	return i.Weight
+}
+
+// Registry 服务注册，实现需在实例存活期间保持注册（如续约租约、上报健康状态）
+type Registry interface {
+	Register(ctx context.Context, instance *Instance) error
+	Deregister(ctx context.Context, instance *Instance) error
+}
+
+// Discovery 服务发现
+type Discovery interface {
+	// GetService 返回服务当前的全部实例
+	GetService(ctx context.Context, name string) ([]*Instance, error)
+	// Watch 监听服务实例变化，ctx 取消后监听结束
+	Watch(ctx context.Context, name string) (Watcher, error)
+}
+
+// Watcher 服务实例监听器
+type Watcher interface {
+	// Next 首次调用立即返回当前实例列表，之后阻塞直到实例列表发生变化
+	Next() ([]*Instance, error)
+	// Stop 停止监听
+	Stop() error
+}
+
+// Registration 服务注册组件，Start 时注册实例，Stop 时注销
+// 注册时应依赖 HTTP 服务器组件，使实例先于服务器停止而从注册中心摘除
+type Registration struct {
+	registry Registry
+	instance *Instance
+	logger   *zhlog.Helper
+}
+
+// NewRegistration 创建服务注册组件
+func NewRegistration(registry Registry, instance *Instance, logger *zhlog.Helper) *Registration {
+	instance.setDefaults()
+	return &Registration{registry: registry, instance: instance, logger: logger}
+}
+
+// Name 组件名称
+func (r *Registration) Name() string {
+	return "discovery-registration"
+}
+
+// Start 注册实例
+func (r *Registration) Start(ctx context.Context) error {
+	if err := r.registry.Register(ctx, r.instance); err != nil {
+		return err
+	}
+	r.logger.Info("服务实例已注册", "service", r.instance.Name, "id", r.instance.ID, "address", r.instance.Address)
+	return nil
+}
+
+// Stop 注销实例
+func (r *Registration) Stop(ctx context.Context) error {
+	if err := r.registry.Deregister(ctx, r.instance); err != nil {
+		return err
+	}
+	r.logger.Info("服务实例已注销", "service", r.instance.Name, "id", r.instance.ID)
+	return nil
+}
@@ -0,0 +1,334 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+)
+
+// EtcdRegistryConfig etcd 注册中心配置
+type EtcdRegistryConfig struct {
+	Prefix  string        // 键前缀，实例保存在 <Prefix><服务名>/<实例ID>，默认 /services/
+	TTL     int           // 租约时间（秒），实例异常退出后最多经过该时间被摘除，默认 15
+	Timeout time.Duration // 单次请求超时时间，默认 5s
+}
+
+// setDefaults 填充默认配置
+func (c *EtcdRegistryConfig) setDefaults() {
+	if c.Prefix == "" {
+		c.Prefix = "/services/"
+	}
+	if !strings.HasSuffix(c.Prefix, "/") {
+		c.Prefix += "/"
+	}
+	if c.TTL <= 0 {
+		c.TTL = 15
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * time.Second
+	}
+}
+
+// EtcdRegistry 基于 etcd 租约的注册中心，同时实现 Registry 和 Discovery
+type EtcdRegistry struct {
+	client *clientv3.Client
+	config *EtcdRegistryConfig
+	logger *zhlog.Helper
+
+	mu    sync.Mutex
+	lease map[string]*etcdLease // 键为实例键
+}
+
+// etcdLease 已注册实例的租约
+type etcdLease struct {
+	mu     sync.Mutex
+	id     clientv3.LeaseID
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewEtcdRegistry 创建 etcd 注册中心
+func NewEtcdRegistry(client *clientv3.Client, config *EtcdRegistryConfig, logger *zhlog.Helper) *EtcdRegistry {
+	config.setDefaults()
+	return &EtcdRegistry{client: client, config: config, logger: logger, lease: make(map[string]*etcdLease)}
+}
+
+// serviceKey 服务的键前缀
+func (r *EtcdRegistry) serviceKey(name string) string {
+	return r.config.Prefix + name + "/"
+}
+
+// Register 在租约下写入实例信息并在后台续约；租约丢失（如网络分区超过 TTL）时自动重新注册
+func (r *EtcdRegistry) Register(ctx context.Context, instance *Instance) error {
+	instance.setDefaults()
+	if instance.Name == "" {
+		return fmt.Errorf("discovery: instance name is empty")
+	}
+	value, err := json.Marshal(instance)
+	if err != nil {
+		return err
+	}
+	key := r.serviceKey(instance.Name) + instance.ID
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.lease[key]; ok {
+		return fmt.Errorf("discovery: instance %s already registered", key)
+	}
+
+	id, err := r.put(ctx, key, string(value))
+	if err != nil {
+		return err
+	}
+
+	loopCtx, cancel := context.WithCancel(context.Background())
+	l := &etcdLease{id: id, cancel: cancel, done: make(chan struct{})}
+	r.lease[key] = l
+	go r.keepAlive(loopCtx, l, key, string(value))
+	return nil
+}
+
+// Deregister 停止续约，删除实例并撤销租约
+func (r *EtcdRegistry) Deregister(ctx context.Context, instance *Instance) error {
+	key := r.serviceKey(instance.Name) + instance.ID
+
+	r.mu.Lock()
+	l, ok := r.lease[key]
+	delete(r.lease, key)
+	r.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	l.cancel()
+	<-l.done
+
+	ctx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+	if _, err := r.client.Delete(ctx, key); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	id := l.id
+	l.mu.Unlock()
+	if _, err := r.client.Revoke(ctx, id); err != nil {
+		r.logger.Warn("撤销实例租约失败", "key", key, "error", err)
+	}
+	return nil
+}
+
+// put 创建租约并写入实例
+func (r *EtcdRegistry) put(ctx context.Context, key, value string) (clientv3.LeaseID, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+
+	grant, err := r.client.Grant(ctx, int64(r.config.TTL))
+	if err != nil {
+		return 0, err
+	}
+	if _, err := r.client.Put(ctx, key, value, clientv3.WithLease(grant.ID)); err != nil {
+		return 0, err
+	}
+	return grant.ID, nil
+}
+
+// keepAlive 续约直到 ctx 取消；续约通道关闭说明租约已失效或连接中断，按退避重新注册
+func (r *EtcdRegistry) keepAlive(ctx context.Context, l *etcdLease, key, value string) {
+	defer close(l.done)
+
+	backoff := time.Second
+	for {
+		l.mu.Lock()
+		id := l.id
+		l.mu.Unlock()
+
+		ch, err := r.client.KeepAlive(ctx, id)
+		if err == nil {
+			for range ch {
+				backoff = time.Second
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		r.logger.Warn("实例租约失效，重新注册", "key", key, "error", err)
+
+		for {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			if backoff < time.Duration(r.config.TTL)*time.Second {
+				backoff *= 2
+			}
+			id, err := r.put(ctx, key, value)
+			if err == nil {
+				l.mu.Lock()
+				l.id = id
+				l.mu.Unlock()
+				r.logger.Info("实例已重新注册", "key", key)
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			r.logger.Warn("实例重新注册失败", "key", key, "error", err)
+		}
+	}
+}
+
+// GetService 返回服务当前的全部实例
+func (r *EtcdRegistry) GetService(ctx context.Context, name string) ([]*Instance, error) {
+	instances, _, err := r.list(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return sortedInstances(instances), nil
+}
+
+// list 读取服务实例，返回以实例键为索引的实例和读取时的版本
+func (r *EtcdRegistry) list(ctx context.Context, name string) (map[string]*Instance, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+
+	resp, err := r.client.Get(ctx, r.serviceKey(name), clientv3.WithPrefix())
+	if err != nil {
+		return nil, 0, err
+	}
+	instances := make(map[string]*Instance, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		if ins, err := decodeInstance(kv.Value); err == nil {
+			instances[string(kv.Key)] = ins
+		} else {
+			r.logger.Warn("忽略无法解析的服务实例", "key", string(kv.Key), "error", err)
+		}
+	}
+	return instances, resp.Header.Revision, nil
+}
+
+// Watch 监听服务实例变化
+func (r *EtcdRegistry) Watch(ctx context.Context, name string) (Watcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &etcdWatcher{registry: r, name: name, ctx: ctx, cancel: cancel}, nil
+}
+
+// etcdWatcher etcd 服务实例监听器
+type etcdWatcher struct {
+	registry *EtcdRegistry
+	name     string
+	ctx      context.Context
+	cancel   context.CancelFunc
+
+	instances   map[string]*Instance
+	wch         clientv3.WatchChan
+	watchCancel context.CancelFunc
+}
+
+// Next 首次调用返回当前实例列表，之后阻塞直到实例变化；watch 被压缩或中断时重新读取全部实例
+func (w *etcdWatcher) Next() ([]*Instance, error) {
+	if w.wch == nil {
+		if err := w.resync(); err != nil {
+			return nil, err
+		}
+		return sortedInstances(w.instances), nil
+	}
+
+	for {
+		select {
+		case resp, ok := <-w.wch:
+			if ok && resp.Err() == nil {
+				if w.apply(resp.Events) {
+					return sortedInstances(w.instances), nil
+				}
+				continue
+			}
+			if w.ctx.Err() != nil {
+				return nil, w.ctx.Err()
+			}
+			if ok {
+				w.registry.logger.Warn("服务实例监听中断，重新同步", "service", w.name, "error", resp.Err())
+			}
+			if err := w.resync(); err != nil {
+				return nil, err
+			}
+			return sortedInstances(w.instances), nil
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		}
+	}
+}
+
+// apply 应用实例变更事件，返回实例列表是否变化
+func (w *etcdWatcher) apply(events []*clientv3.Event) bool {
+	changed := false
+	for _, ev := range events {
+		key := string(ev.Kv.Key)
+		if ev.Type == clientv3.EventTypeDelete {
+			if _, ok := w.instances[key]; ok {
+				delete(w.instances, key)
+				changed = true
+			}
+			continue
+		}
+		ins, err := decodeInstance(ev.Kv.Value)
+		if err != nil {
+			w.registry.logger.Warn("忽略无法解析的服务实例", "key", key, "error", err)
+			continue
+		}
+		w.instances[key] = ins
+		changed = true
+	}
+	return changed
+}
+
+// resync 重新读取全部实例并从读取时的版本开始监听
+func (w *etcdWatcher) resync() error {
+	instances, revision, err := w.registry.list(w.ctx, w.name)
+	if err != nil {
+		return err
+	}
+	w.instances = instances
+
+	if w.watchCancel != nil {
+		w.watchCancel()
+	}
+	watchCtx, cancel := context.WithCancel(w.ctx)
+	w.watchCancel = cancel
+	w.wch = w.registry.client.Watch(clientv3.WithRequireLeader(watchCtx), w.registry.serviceKey(w.name),
+		clientv3.WithPrefix(), clientv3.WithRev(revision+1))
+	return nil
+}
+
+// Stop 停止监听
+func (w *etcdWatcher) Stop() error {
+	w.cancel()
+	return nil
+}
+
+// decodeInstance 解码实例信息
+func decodeInstance(data []byte) (*Instance, error) {
+	ins := &Instance{}
+	if err := json.Unmarshal(data, ins); err != nil {
+		return nil, err
+	}
+	return ins, nil
+}
+
+// sortedInstances 按实例ID排序返回实例列表，保证负载均衡结果稳定
+func sortedInstances(instances map[string]*Instance) []*Instance {
+	list := make([]*Instance, 0, len(instances))
+	for _, ins := range instances {
+		list = append(list, ins)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
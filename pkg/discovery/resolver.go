@@ -0,0 +1,204 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+)
+
+// hashKey 上下文中一致性哈希 key 的键
+type hashKey struct{}
+
+// WithHashKey 设置一致性哈希 key，如用户ID，使同一用户的请求落到同一实例
+func WithHashKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, hashKey{}, key)
+}
+
+// HashKeyFromContext 获取一致性哈希 key
+func HashKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(hashKey{}).(string)
+	return key
+}
+
+// ResolverConfig 服务解析配置
+type ResolverConfig struct {
+	Service  string   // 服务名称
+	Balancer Balancer // 负载均衡器，默认轮询
+	Port     string   // 使用的端口名称，默认 http
+	Version  string   // 只使用指定版本的实例，为空表示不限制
+	Zone     string   // 优先使用该可用区的实例，该可用区没有实例时使用全部实例
+}
+
+// Resolver 服务解析器，监听服务实例并维护可用实例列表，作为应用组件运行
+type Resolver struct {
+	discovery Discovery
+	config    *ResolverConfig
+	logger    *zhlog.Helper
+
+	mu        sync.RWMutex
+	instances []*Instance
+
+	watcher Watcher
+	done    chan struct{}
+}
+
+// NewResolver 创建服务解析器
+func NewResolver(discovery Discovery, config *ResolverConfig, logger *zhlog.Helper) *Resolver {
+	if config.Balancer == nil {
+		config.Balancer = NewRoundRobinBalancer()
+	}
+	if config.Port == "" {
+		config.Port = "http"
+	}
+	return &Resolver{discovery: discovery, config: config, logger: logger}
+}
+
+// Name 组件名称
+func (r *Resolver) Name() string {
+	return "discovery-resolver:" + r.config.Service
+}
+
+// Start 读取当前实例列表并开始监听变化
+func (r *Resolver) Start(ctx context.Context) error {
+	watcher, err := r.discovery.Watch(context.Background(), r.config.Service)
+	if err != nil {
+		return err
+	}
+	instances, err := watcher.Next()
+	if err != nil {
+		_ = watcher.Stop()
+		return err
+	}
+	r.update(instances)
+
+	r.watcher = watcher
+	r.done = make(chan struct{})
+	go r.watch()
+	return nil
+}
+
+// Stop 停止监听
+func (r *Resolver) Stop(ctx context.Context) error {
+	if r.watcher == nil {
+		return nil
+	}
+	_ = r.watcher.Stop()
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// watch 持续接收实例变化，出错时保留当前实例列表并稍后重试
+func (r *Resolver) watch() {
+	defer close(r.done)
+
+	for {
+		instances, err := r.watcher.Next()
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			r.logger.Warn("服务实例监听失败", "service", r.config.Service, "error", err)
+			time.Sleep(time.Second)
+			continue
+		}
+		r.update(instances)
+	}
+}
+
+// update 按版本和可用区筛选实例后更新负载均衡器
+func (r *Resolver) update(instances []*Instance) {
+	selected := make([]*Instance, 0, len(instances))
+	local := make([]*Instance, 0, len(instances))
+	for _, ins := range instances {
+		if r.config.Version != "" && ins.Version != r.config.Version {
+			continue
+		}
+		if _, ok := ins.Ports[r.config.Port]; !ok {
+			continue
+		}
+		selected = append(selected, ins)
+		if r.config.Zone != "" && ins.Zone == r.config.Zone {
+			local = append(local, ins)
+		}
+	}
+	if len(local) > 0 {
+		selected = local
+	}
+
+	r.mu.Lock()
+	r.instances = selected
+	r.mu.Unlock()
+	r.config.Balancer.Update(selected)
+
+	r.logger.Info("服务实例列表已更新", "service", r.config.Service, "instances", len(selected))
+}
+
+// Instances 返回当前可用实例
+func (r *Resolver) Instances() []*Instance {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.instances
+}
+
+// Pick 选择一个实例，一致性哈希使用 WithHashKey 设置的 key
+func (r *Resolver) Pick(ctx context.Context) (*Instance, error) {
+	return r.config.Balancer.Pick(HashKeyFromContext(ctx))
+}
+
+// Endpoint 选择一个实例并返回其 host:port 地址
+func (r *Resolver) Endpoint(ctx context.Context) (string, error) {
+	ins, err := r.Pick(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, r.config.Service)
+	}
+	endpoint, ok := ins.Endpoint(r.config.Port)
+	if !ok {
+		return "", fmt.Errorf("discovery: instance %s has no %s port", ins.ID, r.config.Port)
+	}
+	return endpoint, nil
+}
+
+// Transport 按服务名称解析请求地址的 HTTP 传输层
+// 请求 http://user-service/api/v1/users 时由 user-service 的解析器选择实例，其他主机的请求原样发送
+type Transport struct {
+	base      http.RoundTripper
+	resolvers map[string]*Resolver
+}
+
+// NewTransport 创建服务发现 HTTP 传输层，base 为 nil 时使用 http.DefaultTransport
+func NewTransport(base http.RoundTripper, resolvers ...*Resolver) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &Transport{base: base, resolvers: make(map[string]*Resolver, len(resolvers))}
+	for _, r := range resolvers {
+		t.resolvers[r.config.Service] = r
+	}
+	return t
+}
+
+// RoundTrip 将服务名称替换为所选实例的地址后发送请求
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r, ok := t.resolvers[req.URL.Hostname()]
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	endpoint, err := r.Endpoint(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.URL.Host = endpoint
+	req.Host = ""
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,427 @@
+package dynconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/utils/common"
+)
+
+// SourceConfig 配置来源，优先级从低到高依次为 TOML 文件、环境变量（含 .env 文件）、etcd
+type SourceConfig struct {
+	File           string           // TOML 配置文件路径，为空时从零值开始
+	EnvFile        string           // .env 文件路径，不覆盖已存在的环境变量，为空或文件不存在时跳过
+	EnvPrefix      string           // 环境变量前缀，默认 APP_；APP_LOGGING__LEVEL 对应 [logging] level，层级之间以 __ 分隔
+	Client         *clientv3.Client // etcd 客户端，为 nil 时不使用 etcd，也不支持热更新
+	EtcdPrefix     string           // etcd 键前缀，如 /config/my-service/；键 logging/level 对应 [logging] level，键 logging 可保存整个 [logging] 段的 TOML
+	RequestTimeout time.Duration    // etcd 请求超时时间，默认 5s
+}
+
+// setDefaults 填充默认配置
+func (c *SourceConfig) setDefaults() {
+	if c.EnvPrefix == "" {
+		c.EnvPrefix = "APP_"
+	}
+	if c.EtcdPrefix != "" && !strings.HasSuffix(c.EtcdPrefix, "/") {
+		c.EtcdPrefix += "/"
+	}
+	if c.RequestTimeout <= 0 {
+		c.RequestTimeout = 5 * time.Second
+	}
+}
+
+// subscriber 配置段订阅者
+type subscriber struct {
+	section string
+	index   int
+	notify  func(old, new reflect.Value) error
+}
+
+// Manager 动态配置管理器，T 为应用配置结构体，顶层字段即配置段（如 [security]、[logging]）
+// 监听 etcd 键前缀的变化，重新合并所有来源并校验，校验通过后通知发生变化的配置段的订阅者；
+// 任一订阅者返回错误时，已通知的订阅者按相反顺序收到回滚通知，当前配置保持不变
+type Manager[T any] struct {
+	config *SourceConfig
+	logger *zhlog.Helper
+
+	mu          sync.RWMutex
+	current     *T
+	subscribers []subscriber
+
+	reloadMu sync.Mutex
+	file     []byte            // TOML 文件内容
+	kvs      map[string]string // etcd 键前缀下的键值（键不含前缀）
+	revision int64             // kvs 对应的 etcd 版本
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// NewManager 创建动态配置管理器并加载初始配置，任一来源加载失败或校验失败时返回错误
+func NewManager[T any](ctx context.Context, config *SourceConfig, logger *zhlog.Helper) (*Manager[T], error) {
+	if t := reflect.TypeOf((*T)(nil)).Elem(); t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("dynconfig: config type %s is not a struct", t)
+	}
+	config.setDefaults()
+
+	m := &Manager[T]{config: config, logger: logger}
+	if config.EnvFile != "" {
+		if err := godotenv.Load(config.EnvFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("dynconfig: load env file: %w", err)
+		}
+	}
+	if err := m.readFile(); err != nil {
+		return nil, err
+	}
+	if err := m.fetch(ctx); err != nil {
+		return nil, err
+	}
+
+	current, err := m.build()
+	if err != nil {
+		return nil, err
+	}
+	m.current = current
+	return m, nil
+}
+
+// Current 返回当前生效的配置，调用方不应修改返回值
+func (m *Manager[T]) Current() *T {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.current
+}
+
+// Subscribe 订阅配置段的变化，section 为配置段的 TOML 键名（如 security），S 必须与该段字段类型一致
+// 配置更新时 fn 收到旧值和新值，返回错误将回滚本次更新；回滚时已成功的订阅者会再次收到 (新值, 旧值)
+// fn 在配置生效前调用，此时 Current 仍返回旧配置
+func Subscribe[T, S any](m *Manager[T], section string, fn func(old, new S) error) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	index := -1
+	for i := 0; i < t.NumField(); i++ {
+		if sameField(t, i, section) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("dynconfig: unknown section %q", section)
+	}
+	if want := reflect.TypeOf((*S)(nil)).Elem(); t.Field(index).Type != want {
+		return fmt.Errorf("dynconfig: section %q is %s, not %s", section, t.Field(index).Type, want)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribers = append(m.subscribers, subscriber{
+		section: section,
+		index:   index,
+		notify: func(old, new reflect.Value) error {
+			return fn(old.Interface().(S), new.Interface().(S))
+		},
+	})
+	return nil
+}
+
+// sameField 判断 section 是否对应 T 的第 i 个顶层字段
+func sameField(t reflect.Type, i int, section string) bool {
+	sf := t.Field(i)
+	name := tomlName(sf)
+	if name == "" {
+		name = sf.Name
+	}
+	return sf.IsExported() && name != "-" && (strings.EqualFold(name, section) || strings.EqualFold(sf.Name, strings.ReplaceAll(section, "_", "")))
+}
+
+// Reload 重新读取 TOML 文件和 etcd 并应用配置，可用于响应 SIGHUP
+func (m *Manager[T]) Reload(ctx context.Context) error {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	if err := m.readFile(); err != nil {
+		return err
+	}
+	if err := m.fetch(ctx); err != nil {
+		return err
+	}
+	return m.apply()
+}
+
+// Name 组件名称
+func (m *Manager[T]) Name() string {
+	return "dynconfig"
+}
+
+// Start 开始监听 etcd 键前缀，未配置 etcd 时不做任何事
+func (m *Manager[T]) Start(ctx context.Context) error {
+	if m.config.Client == nil || m.config.EtcdPrefix == "" {
+		return nil
+	}
+
+	watchCtx, stop := context.WithCancel(context.Background())
+	m.stop = stop
+	m.done = make(chan struct{})
+	go m.watch(watchCtx)
+
+	m.logger.Info("动态配置监听已启动", "prefix", m.config.EtcdPrefix)
+	return nil
+}
+
+// Stop 停止监听
+func (m *Manager[T]) Stop(ctx context.Context) error {
+	if m.stop == nil {
+		return nil
+	}
+	m.stop()
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// watch 监听 etcd 变化；watch 被压缩或中断时重新读取全部键值后从新版本继续
+func (m *Manager[T]) watch(ctx context.Context) {
+	defer close(m.done)
+
+	for ctx.Err() == nil {
+		m.reloadMu.Lock()
+		revision := m.revision
+		m.reloadMu.Unlock()
+
+		wch := m.config.Client.Watch(clientv3.WithRequireLeader(ctx), m.config.EtcdPrefix,
+			clientv3.WithPrefix(), clientv3.WithRev(revision+1))
+		for resp := range wch {
+			if err := resp.Err(); err != nil {
+				m.logger.Warn("动态配置监听中断", "prefix", m.config.EtcdPrefix, "error", err)
+				break
+			}
+			m.handle(resp.Events, resp.Header.Revision)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return
+		}
+		if err := m.resync(ctx); err != nil {
+			m.logger.Warn("动态配置重新同步失败", "prefix", m.config.EtcdPrefix, "error", err)
+		}
+	}
+}
+
+// handle 应用一批 etcd 事件
+func (m *Manager[T]) handle(events []*clientv3.Event, revision int64) {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	kvs := make(map[string]string, len(m.kvs))
+	for k, v := range m.kvs {
+		kvs[k] = v
+	}
+	for _, ev := range events {
+		key := strings.TrimPrefix(string(ev.Kv.Key), m.config.EtcdPrefix)
+		if ev.Type == clientv3.EventTypeDelete {
+			delete(kvs, key)
+		} else {
+			kvs[key] = string(ev.Kv.Value)
+		}
+	}
+	// 无论新配置是否生效都记录 etcd 的最新状态，etcd 中的错误修复后下一次变更即可生效
+	m.kvs = kvs
+	m.revision = revision
+
+	if err := m.apply(); err != nil {
+		m.logger.Error("动态配置更新失败，保持当前配置", "prefix", m.config.EtcdPrefix, "revision", revision, "error", err)
+	}
+}
+
+// resync 重新读取 etcd 全部键值并应用配置
+func (m *Manager[T]) resync(ctx context.Context) error {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	if err := m.fetch(ctx); err != nil {
+		return err
+	}
+	return m.apply()
+}
+
+// apply 重新合并配置，校验通过后通知订阅者并生效，调用方需持有 reloadMu
+func (m *Manager[T]) apply() error {
+	next, err := m.build()
+	if err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	prev := m.current
+	subscribers := m.subscribers
+	m.mu.RUnlock()
+
+	oldValue := reflect.ValueOf(prev).Elem()
+	newValue := reflect.ValueOf(next).Elem()
+	changed := map[int]bool{}
+	for i := 0; i < oldValue.NumField(); i++ {
+		if oldValue.Type().Field(i).IsExported() && !reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			changed[i] = true
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	notified := make([]subscriber, 0, len(subscribers))
+	for _, s := range subscribers {
+		if !changed[s.index] {
+			continue
+		}
+		if err := s.notify(oldValue.Field(s.index), newValue.Field(s.index)); err != nil {
+			m.rollback(notified, oldValue, newValue)
+			return fmt.Errorf("dynconfig: section %q rejected: %w", s.section, err)
+		}
+		notified = append(notified, s)
+	}
+
+	m.mu.Lock()
+	m.current = next
+	m.mu.Unlock()
+
+	sections := make([]string, 0, len(changed))
+	for i := range changed {
+		sections = append(sections, oldValue.Type().Field(i).Name)
+	}
+	sort.Strings(sections)
+	m.logger.Info("动态配置已更新", "sections", sections, "revision", m.revision)
+	return nil
+}
+
+// rollback 按相反顺序通知已成功的订阅者恢复旧值
+func (m *Manager[T]) rollback(notified []subscriber, oldValue, newValue reflect.Value) {
+	for i := len(notified) - 1; i >= 0; i-- {
+		s := notified[i]
+		if err := s.notify(newValue.Field(s.index), oldValue.Field(s.index)); err != nil {
+			m.logger.Error("动态配置回滚失败", "section", s.section, "error", err)
+		}
+	}
+}
+
+// build 按优先级合并所有来源并校验
+func (m *Manager[T]) build() (*T, error) {
+	cfg := new(T)
+	if len(m.file) > 0 {
+		if err := toml.Unmarshal(m.file, cfg); err != nil {
+			return nil, fmt.Errorf("dynconfig: decode %s: %w", m.config.File, err)
+		}
+	}
+
+	v := reflect.ValueOf(cfg).Elem()
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, m.config.EnvPrefix) {
+			continue
+		}
+		path := strings.Split(strings.ToLower(strings.TrimPrefix(name, m.config.EnvPrefix)), "__")
+		if err := m.override(v, path, value); err != nil {
+			return nil, fmt.Errorf("dynconfig: env %s: %w", name, err)
+		}
+	}
+
+	// 较短的路径先应用，键 logging 中的整段配置可被键 logging/level 覆盖
+	keys := make([]string, 0, len(m.kvs))
+	for k := range m.kvs {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		di, dj := strings.Count(keys[i], "/"), strings.Count(keys[j], "/")
+		if di != dj {
+			return di < dj
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		path := strings.Split(strings.Trim(key, "/"), "/")
+		if err := m.override(v, path, m.kvs[key]); err != nil {
+			return nil, fmt.Errorf("dynconfig: etcd key %s%s: %w", m.config.EtcdPrefix, key, err)
+		}
+	}
+
+	if err := validate(cfg); err != nil {
+		return nil, fmt.Errorf("dynconfig: invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+// override 应用单个覆盖值，配置中不存在的键记录警告后忽略
+func (m *Manager[T]) override(v reflect.Value, path []string, raw string) error {
+	if len(path) == 0 || path[0] == "" {
+		return nil
+	}
+	if _, ok := fieldByKey(v, path[0]); !ok {
+		m.logger.Warn("忽略未知的配置项", "key", strings.Join(path, "."))
+		return nil
+	}
+	return setPath(v, path, raw)
+}
+
+// validate 使用 validate 标签校验配置，配置实现 Validate() error 时一并调用
+func validate(cfg interface{}) error {
+	if err := common.Validator().Struct(cfg); err != nil {
+		return err
+	}
+	if v, ok := cfg.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// readFile 读取 TOML 配置文件
+func (m *Manager[T]) readFile() error {
+	if m.config.File == "" {
+		return nil
+	}
+	data, err := os.ReadFile(m.config.File)
+	if err != nil {
+		return fmt.Errorf("dynconfig: read %s: %w", m.config.File, err)
+	}
+	m.file = data
+	return nil
+}
+
+// fetch 读取 etcd 键前缀下的全部键值
+func (m *Manager[T]) fetch(ctx context.Context) error {
+	if m.config.Client == nil || m.config.EtcdPrefix == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.config.RequestTimeout)
+	defer cancel()
+
+	resp, err := m.config.Client.Get(ctx, m.config.EtcdPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return fmt.Errorf("dynconfig: get %s: %w", m.config.EtcdPrefix, err)
+	}
+	kvs := make(map[string]string, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		kvs[strings.TrimPrefix(string(kv.Key), m.config.EtcdPrefix)] = string(kv.Value)
+	}
+	m.kvs = kvs
+	m.revision = resp.Header.Revision
+	return nil
+}
@@ -0,0 +1,186 @@
+package dynconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// durationType time.Duration 的类型，按 "30s"、"5m" 格式解析
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setPath 按路径（TOML 键名）设置配置字段，值按字段类型解析：
+// 字符串原样使用，数字和布尔值按字面量解析，time.Duration 按 "30s" 格式解析，
+// 切片接受 TOML 数组或逗号分隔的列表，结构体和 map 接受 TOML 文档（只覆盖文档中出现的键）
+func setPath(v reflect.Value, path []string, raw string) error {
+	if len(path) == 0 {
+		return setValue(v, raw)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setPath(v.Elem(), path, raw)
+	case reflect.Struct:
+		field, ok := fieldByKey(v, path[0])
+		if !ok {
+			return fmt.Errorf("unknown key %q", path[0])
+		}
+		return setPath(field, path[1:], raw)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key type %s", v.Type().Key())
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		key := reflect.ValueOf(path[0]).Convert(v.Type().Key())
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := setPath(elem, path[1:], raw); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+	default:
+		return fmt.Errorf("key %q: cannot descend into %s", path[0], v.Type())
+	}
+}
+
+// setValue 将字符串解析为字段类型并赋值
+func setValue(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(unquote(raw))
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(unquote(raw))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if strings.HasPrefix(raw, "[") {
+			return decodeTOMLValue(v, raw)
+		}
+		parts := strings.Split(raw, ",")
+		slice := reflect.MakeSlice(v.Type(), 0, len(parts))
+		for _, part := range parts {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(elem, part); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		v.Set(slice)
+	case reflect.Struct, reflect.Map:
+		return toml.Unmarshal([]byte(raw), v.Addr().Interface())
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), raw)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// decodeTOMLValue 将 TOML 字面量（如数组）解码到字段
+func decodeTOMLValue(v reflect.Value, raw string) error {
+	holder := reflect.New(reflect.StructOf([]reflect.StructField{{
+		Name: "V",
+		Type: v.Type(),
+		Tag:  `toml:"v"`,
+	}}))
+	if err := toml.Unmarshal([]byte("v = "+raw), holder.Interface()); err != nil {
+		return err
+	}
+	v.Set(holder.Elem().Field(0))
+	return nil
+}
+
+// fieldByKey 按 TOML 键名查找结构体字段，未声明 toml 标签时按字段名匹配，均不区分大小写
+// 嵌入的结构体字段（如 CORSConfig 中的 CORSPolicy）视为同一层级
+func fieldByKey(v reflect.Value, key string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := tomlName(sf)
+		if name == "-" {
+			continue
+		}
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+			if field, ok := fieldByKey(v.Field(i), key); ok {
+				return field, true
+			}
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if strings.EqualFold(name, key) || strings.EqualFold(sf.Name, strings.ReplaceAll(key, "_", "")) {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// tomlName 返回字段的 toml 标签名
+func tomlName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("toml"), ",")
+	return name
+}
+
+// unquote 去掉 TOML 字符串字面量的引号
+func unquote(raw string) string {
+	if len(raw) >= 2 && (raw[0] == '"' && raw[len(raw)-1] == '"' || raw[0] == '\'' && raw[len(raw)-1] == '\'') {
+		if s, err := strconv.Unquote(raw); err == nil {
+			return s
+		}
+		return raw[1 : len(raw)-1]
+	}
+	return raw
+}
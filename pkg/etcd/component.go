@@ -0,0 +1,55 @@
+package etcd
+
+import (
+	"context"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+)
+
+// Component Etcd应用组件
+// 创建时初始化客户端（后台建立连接），Start 时检查集群是否可用，Stop 时关闭客户端
+type Component struct {
+	client *clientv3.Client
+	logger *zhlog.Helper
+}
+
+// NewComponent 创建Etcd组件
+func NewComponent(config *EtcdConfig, logger *zhlog.Helper) (*Component, error) {
+	clientConfig, err := newClientConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	client, err := clientv3.New(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Component{client: client, logger: logger}, nil
+}
+
+// Name 组件名称
+func (c *Component) Name() string {
+	return "etcd"
+}
+
+// Start 检查Etcd集群是否可用
+func (c *Component) Start(ctx context.Context) error {
+	if err := HealthCheck(c.client).Checker.Check(ctx); err != nil {
+		return err
+	}
+
+	c.logger.Info("Etcd 连接成功")
+	return nil
+}
+
+// Stop 关闭客户端
+func (c *Component) Stop(ctx context.Context) error {
+	return c.client.Close()
+}
+
+// Client 返回Etcd客户端
+func (c *Component) Client() *clientv3.Client {
+	return c.client
+}
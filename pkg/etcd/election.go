@@ -0,0 +1,134 @@
+package etcd
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+)
+
+// ElectionConfig leader 选举配置
+type ElectionConfig struct {
+	Key string // 选举键前缀，参与同一选举的副本必须相同，如 /election/my-service/scheduler
+	ID  string // 候选者标识，默认 主机名-进程号
+	TTL int    // 租约时间（秒），leader 异常退出后最多经过该时间完成切换，默认 10
+}
+
+// Election 基于 etcd 租约的 leader 选举
+// 同一时刻最多只有一个副本持有领导权；租约失效（网络分区、进程退出）时领导权自动释放，其余副本重新竞选
+type Election struct {
+	client *clientv3.Client
+	config *ElectionConfig
+	logger *zhlog.Helper
+
+	mu        sync.RWMutex
+	leaderCtx context.Context
+	done      chan struct{}
+}
+
+// NewElection 创建 leader 选举
+func NewElection(client *clientv3.Client, config *ElectionConfig, logger *zhlog.Helper) *Election {
+	if config.ID == "" {
+		host, _ := os.Hostname()
+		config.ID = host + "-" + strconv.Itoa(os.Getpid())
+	}
+	if config.TTL <= 0 {
+		config.TTL = 10
+	}
+	return &Election{client: client, config: config, logger: logger, done: make(chan struct{})}
+}
+
+// Run 持续参与选举直到 ctx 取消，退出前主动放弃领导权；每个 Election 只能运行一次
+func (e *Election) Run(ctx context.Context) {
+	defer close(e.done)
+	for ctx.Err() == nil {
+		if err := e.campaign(ctx); err != nil && ctx.Err() == nil {
+			e.logger.Warn("leader 选举失败，稍后重试", "key", e.config.Key, "error", err)
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+			}
+		}
+	}
+}
+
+// campaign 创建租约并竞选，成为 leader 后保持领导权直到租约失效或 ctx 取消
+func (e *Election) campaign(ctx context.Context) error {
+	// 会话使用不随 ctx 取消的上下文，保证退出时 session.Close 能撤销租约；仅在创建会话期间随 ctx 取消
+	sessionCtx, cancelSession := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelSession()
+	stop := context.AfterFunc(ctx, cancelSession)
+	session, err := concurrency.NewSession(e.client, concurrency.WithTTL(e.config.TTL), concurrency.WithContext(sessionCtx))
+	if !stop() {
+		// 创建期间已退出，会话的续约随 cancelSession 停止，尚未竞选，租约到期后自动释放
+		return ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+	// Close 在 sessionCtx 上以 TTL 为超时撤销租约，不受 ctx 取消影响
+	defer session.Close()
+
+	election := concurrency.NewElection(session, e.config.Key)
+	if err := election.Campaign(ctx, e.config.ID); err != nil {
+		return err
+	}
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	e.setLeader(leaderCtx)
+	e.logger.Info("成为 leader", "key", e.config.Key, "id", e.config.ID)
+
+	select {
+	case <-session.Done():
+	case <-ctx.Done():
+	}
+
+	// 两者同时就绪时优先按退出处理，主动放弃领导权
+	if ctx.Err() != nil {
+		resignCtx, resignCancel := context.WithTimeout(context.Background(), time.Duration(e.config.TTL)*time.Second)
+		if err := election.Resign(resignCtx); err != nil {
+			e.logger.Warn("放弃领导权失败", "key", e.config.Key, "error", err)
+		}
+		resignCancel()
+	} else {
+		e.logger.Warn("leader 租约失效，失去领导权", "key", e.config.Key, "id", e.config.ID)
+	}
+
+	e.setLeader(nil)
+	cancel()
+	return nil
+}
+
+// Done 返回 Run 退出（已放弃领导权并关闭会话）时关闭的通道
+func (e *Election) Done() <-chan struct{} {
+	return e.done
+}
+
+// setLeader 更新当前领导权上下文
+func (e *Election) setLeader(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.leaderCtx = ctx
+}
+
+// IsLeader 当前副本是否为 leader
+func (e *Election) IsLeader() bool {
+	_, ok := e.LeaderContext()
+	return ok
+}
+
+// LeaderContext 返回领导权上下文，失去领导权时该上下文被取消；当前副本不是 leader 时返回 false
+func (e *Election) LeaderContext() (context.Context, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.leaderCtx == nil || e.leaderCtx.Err() != nil {
+		return nil, false
+	}
+	return e.leaderCtx, true
+}
@@ -1,30 +1,159 @@
 package etcd
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
 	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
 
 	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/health"
 )
 
 type EtcdConfig struct {
 	Endpoints   []string
-	DialTimeout int
+	DialTimeout int // 连接超时时间（秒），默认 5
+	// 认证信息，为空时不启用认证
+	Username string
+	Password string
+	// TLS 配置，TLSEnabled 为 true 时启用；CertFile/KeyFile 用于双向认证，CAFile 为空时使用系统根证书
+	TLSEnabled         bool
+	CertFile           string
+	KeyFile            string
+	CAFile             string
+	ServerName         string // 校验服务端证书时使用的主机名，默认取自 Endpoints
+	InsecureSkipVerify bool   // 跳过服务端证书校验，仅用于测试环境
+	// 连接保活：超过 KeepAliveTime 没有活动时发送 ping，KeepAliveTimeout 内无响应则断开重连
+	KeepAliveTime    time.Duration // 默认 30s
+	KeepAliveTimeout time.Duration // 默认 10s
+	AutoSyncInterval time.Duration // 从集群同步成员列表的间隔，0 表示不同步
+	// 单次请求大小限制（字节）
+	MaxCallSendMsgSize int // 默认 2MiB，需小于服务端 --max-request-bytes
+	MaxCallRecvMsgSize int // 默认不限制
 }
 
-func NewEtcd(config *EtcdConfig, logger *zhlog.Helper) *clientv3.Client {
-	etcdClient, err := clientv3.New(clientv3.Config{
-		Endpoints:   config.Endpoints,                                // Etcd服务器地址
-		DialTimeout: time.Duration(config.DialTimeout) * time.Second, // 连接超时时间
-	})
+// NewEtcd 创建 Etcd 客户端并检查集群是否可用，失败时返回错误
+func NewEtcd(config *EtcdConfig, logger *zhlog.Helper) (*clientv3.Client, error) {
+	clientConfig, err := newClientConfig(config)
+	if err != nil {
+		logger.Error("Etcd 配置无效", "error", err)
+		return nil, err
+	}
 
+	etcdClient, err := clientv3.New(clientConfig)
 	if err != nil {
-		logger.Error("Etcd 连接失败", err)
-		panic(err)
+		logger.Error("Etcd 连接失败", "error", err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clientConfig.DialTimeout)
+	defer cancel()
+
+	if err := HealthCheck(etcdClient).Checker.Check(ctx); err != nil {
+		logger.Error("Etcd 连接失败", "error", err)
+		_ = etcdClient.Close()
+		return nil, err
 	}
 
 	logger.Info("Etcd 连接成功")
 
-	return etcdClient
+	return etcdClient, nil
+}
+
+// newClientConfig 根据配置创建客户端配置
+func newClientConfig(config *EtcdConfig) (clientv3.Config, error) {
+	dialTimeout := time.Duration(config.DialTimeout) * time.Second
+	if dialTimeout <= 0 {
+		dialTimeout = 5 * time.Second
+	}
+	keepAliveTime := config.KeepAliveTime
+	if keepAliveTime <= 0 {
+		keepAliveTime = 30 * time.Second
+	}
+	keepAliveTimeout := config.KeepAliveTimeout
+	if keepAliveTimeout <= 0 {
+		keepAliveTimeout = 10 * time.Second
+	}
+
+	clientConfig := clientv3.Config{
+		Endpoints:            config.Endpoints,          // Etcd服务器地址
+		DialTimeout:          dialTimeout,               // 连接超时时间
+		DialKeepAliveTime:    keepAliveTime,             // 保活 ping 间隔
+		DialKeepAliveTimeout: keepAliveTimeout,          // 保活 ping 超时时间
+		PermitWithoutStream:  true,                      // 没有活动请求时也发送保活 ping，及时发现断开的连接
+		AutoSyncInterval:     config.AutoSyncInterval,   // 成员列表同步间隔
+		MaxCallSendMsgSize:   config.MaxCallSendMsgSize, // 单次请求大小限制
+		MaxCallRecvMsgSize:   config.MaxCallRecvMsgSize, // 单次响应大小限制
+		Username:             config.Username,           // 用户名
+		Password:             config.Password,           // 密码
+	}
+
+	if config.TLSEnabled {
+		tlsConfig, err := newTLSConfig(config)
+		if err != nil {
+			return clientv3.Config{}, err
+		}
+		clientConfig.TLS = tlsConfig
+	}
+	return clientConfig, nil
+}
+
+// newTLSConfig 根据证书路径创建 TLS 配置
+func newTLSConfig(config *EtcdConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("etcd: load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("etcd: read ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("etcd: no certificates found in %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// HealthCheck 返回Etcd健康检查项，任一节点可用即视为健康
+// Etcd 通常用于服务发现和配置中心，默认作为非关键依赖
+func HealthCheck(client *clientv3.Client) health.Check {
+	return health.Check{
+		Name:     "etcd",
+		Timeout:  2 * time.Second,
+		Critical: false,
+		Checker: health.CheckerFunc(func(ctx context.Context) error {
+			var errs []error
+			for _, endpoint := range client.Endpoints() {
+				if _, err := client.Status(ctx, endpoint); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				return nil
+			}
+			if len(errs) == 0 {
+				return errors.New("no etcd endpoints configured")
+			}
+			return errors.Join(errs...)
+		}),
+	}
 }
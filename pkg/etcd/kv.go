@@ -0,0 +1,133 @@
+package etcd
+
+import (
+	"context"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"go-template/pkg/kv"
+)
+
+// KVStore 基于 etcd 的键值存储，实现 kv.Store
+type KVStore struct {
+	client *clientv3.Client
+}
+
+// NewKVStore 创建 etcd 键值存储
+func NewKVStore(client *clientv3.Client) *KVStore {
+	return &KVStore{client: client}
+}
+
+// Get 读取键值，键不存在时返回 kv.ErrNotFound
+func (s *KVStore) Get(ctx context.Context, key string) (*kv.Entry, error) {
+	resp, err := s.client.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, kv.ErrNotFound
+	}
+	return &kv.Entry{Key: key, Value: resp.Kvs[0].Value, Revision: resp.Kvs[0].ModRevision}, nil
+}
+
+// Put 写入键值
+func (s *KVStore) Put(ctx context.Context, key string, value []byte) error {
+	_, err := s.client.Put(ctx, key, string(value))
+	return err
+}
+
+// CompareAndSwap 仅当键的 ModRevision 等于 revision 时写入，revision 为 0 表示仅当键不存在时写入
+func (s *KVStore) CompareAndSwap(ctx context.Context, key string, value []byte, revision int64) (bool, error) {
+	resp, err := compareAndSwap(ctx, s.client, key, value, revision)
+	if err != nil {
+		return false, err
+	}
+	return resp.Succeeded, nil
+}
+
+// compareAndSwap 以事务实现比较并交换，KVStore 和 TypedKV 共用
+func compareAndSwap(ctx context.Context, client *clientv3.Client, key string, value []byte, revision int64) (*clientv3.TxnResponse, error) {
+	cmp := clientv3.Compare(clientv3.ModRevision(key), "=", revision)
+	if revision == 0 {
+		cmp = clientv3.Compare(clientv3.CreateRevision(key), "=", 0)
+	}
+	return client.Txn(ctx).If(cmp).Then(clientv3.OpPut(key, string(value))).Commit()
+}
+
+// Delete 删除键
+func (s *KVStore) Delete(ctx context.Context, key string) error {
+	_, err := s.client.Delete(ctx, key)
+	return err
+}
+
+// Watch 监听键的变化
+func (s *KVStore) Watch(ctx context.Context, key string) (kv.Watcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &kvWatcher{client: s.client, key: key, ctx: ctx, cancel: cancel}, nil
+}
+
+// kvWatcher etcd 键监听器
+type kvWatcher struct {
+	client *clientv3.Client
+	key    string
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	wch         clientv3.WatchChan
+	watchCancel context.CancelFunc
+}
+
+// Next 首次调用返回当前值，之后阻塞直到键变化；watch 被压缩或中断时重新读取当前值后继续
+func (w *kvWatcher) Next() (*kv.Entry, error) {
+	if w.wch == nil {
+		return w.resync()
+	}
+
+	for {
+		select {
+		case resp, ok := <-w.wch:
+			if ok && resp.Err() == nil {
+				if len(resp.Events) == 0 {
+					continue
+				}
+				ev := resp.Events[len(resp.Events)-1]
+				if ev.Type == clientv3.EventTypeDelete {
+					return &kv.Entry{Key: w.key, Revision: ev.Kv.ModRevision}, nil
+				}
+				return &kv.Entry{Key: w.key, Value: ev.Kv.Value, Revision: ev.Kv.ModRevision}, nil
+			}
+			if w.ctx.Err() != nil {
+				return nil, w.ctx.Err()
+			}
+			return w.resync()
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		}
+	}
+}
+
+// resync 读取当前值并从读取时的版本开始监听
+func (w *kvWatcher) resync() (*kv.Entry, error) {
+	resp, err := w.client.Get(w.ctx, w.key)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.watchCancel != nil {
+		w.watchCancel()
+	}
+	watchCtx, cancel := context.WithCancel(w.ctx)
+	w.watchCancel = cancel
+	w.wch = w.client.Watch(clientv3.WithRequireLeader(watchCtx), w.key, clientv3.WithRev(resp.Header.Revision+1))
+
+	if len(resp.Kvs) == 0 {
+		return &kv.Entry{Key: w.key}, nil
+	}
+	return &kv.Entry{Key: w.key, Value: resp.Kvs[0].Value, Revision: resp.Kvs[0].ModRevision}, nil
+}
+
+// Stop 停止监听
+func (w *kvWatcher) Stop() error {
+	w.cancel()
+	return nil
+}
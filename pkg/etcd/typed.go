@@ -0,0 +1,298 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/kv"
+)
+
+// maxUpdateAttempts Update 因并发修改而重试的最大次数
+const maxUpdateAttempts = 16
+
+// ErrConflict Update 多次重试后仍因并发修改失败
+var ErrConflict = errors.New("etcd: too many concurrent modifications")
+
+// Codec 值编解码器
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec JSON 编解码器
+type JSONCodec struct{}
+
+// Marshal 编码为 JSON
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal 从 JSON 解码
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// EventType 监听事件类型
+type EventType int
+
+const (
+	EventPut    EventType = iota // 新增或修改
+	EventDelete                  // 删除
+	EventSync                    // 一次全量同步完成，此时已收到的事件与同步时的状态一致
+)
+
+// Event 类型化监听事件
+type Event[T any] struct {
+	Type     EventType
+	Key      string // 不含前缀的键
+	Value    T      // 删除事件为零值
+	Revision int64  // 事件对应的 ModRevision，压缩后重新同步产生的删除事件为同步时的版本
+}
+
+// TypedKV 类型化键值存储，键位于 prefix 之下，值使用 Codec 编解码（默认 JSON）
+type TypedKV[T any] struct {
+	client *clientv3.Client
+	prefix string
+	codec  Codec
+	logger *zhlog.Helper
+}
+
+// NewTypedKV 创建类型化键值存储，codec 为 nil 时使用 JSON
+func NewTypedKV[T any](client *clientv3.Client, prefix string, codec Codec, logger *zhlog.Helper) *TypedKV[T] {
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	return &TypedKV[T]{client: client, prefix: prefix, codec: codec, logger: logger}
+}
+
+// Get 读取并解码值，返回值和 ModRevision；键不存在时返回 kv.ErrNotFound
+func (s *TypedKV[T]) Get(ctx context.Context, key string) (T, int64, error) {
+	var zero T
+	resp, err := s.client.Get(ctx, s.prefix+key)
+	if err != nil {
+		return zero, 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return zero, 0, kv.ErrNotFound
+	}
+	v, err := s.decode(resp.Kvs[0].Value)
+	if err != nil {
+		return zero, 0, fmt.Errorf("etcd: decode %s: %w", resp.Kvs[0].Key, err)
+	}
+	return v, resp.Kvs[0].ModRevision, nil
+}
+
+// List 读取前缀下的全部值，键不含前缀；无法解码的值记录警告后跳过
+func (s *TypedKV[T]) List(ctx context.Context) (map[string]T, int64, error) {
+	resp, err := s.client.Get(ctx, s.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, 0, err
+	}
+	values := make(map[string]T, len(resp.Kvs))
+	for _, item := range resp.Kvs {
+		v, err := s.decode(item.Value)
+		if err != nil {
+			s.logger.Warn("忽略无法解码的值", "key", string(item.Key), "error", err)
+			continue
+		}
+		values[strings.TrimPrefix(string(item.Key), s.prefix)] = v
+	}
+	return values, resp.Header.Revision, nil
+}
+
+// Put 编码并写入值，返回写入后的版本；opts 可传入 clientv3.WithLease 等选项
+func (s *TypedKV[T]) Put(ctx context.Context, key string, v T, opts ...clientv3.OpOption) (int64, error) {
+	data, err := s.codec.Marshal(v)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := s.client.Put(ctx, s.prefix+key, string(data), opts...)
+	if err != nil {
+		return 0, err
+	}
+	return resp.Header.Revision, nil
+}
+
+// CompareAndSwap 仅当键的 ModRevision 等于 revision 时写入，revision 为 0 表示仅当键不存在时写入
+// 返回是否写入成功以及写入后的版本
+func (s *TypedKV[T]) CompareAndSwap(ctx context.Context, key string, v T, revision int64) (bool, int64, error) {
+	data, err := s.codec.Marshal(v)
+	if err != nil {
+		return false, 0, err
+	}
+	resp, err := compareAndSwap(ctx, s.client, s.prefix+key, data, revision)
+	if err != nil {
+		return false, 0, err
+	}
+	return resp.Succeeded, resp.Header.Revision, nil
+}
+
+// Update 读取当前值交给 fn 修改后以比较并交换写回，期间被并发修改时重新读取并重试
+// 键不存在时 fn 收到零值且 exists 为 false；fn 返回错误时放弃更新
+func (s *TypedKV[T]) Update(ctx context.Context, key string, fn func(current T, exists bool) (T, error)) (T, error) {
+	var zero T
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		current, revision, err := s.Get(ctx, key)
+		exists := err == nil
+		if err != nil && !errors.Is(err, kv.ErrNotFound) {
+			return zero, err
+		}
+
+		next, err := fn(current, exists)
+		if err != nil {
+			return zero, err
+		}
+		ok, _, err := s.CompareAndSwap(ctx, key, next, revision)
+		if err != nil {
+			return zero, err
+		}
+		if ok {
+			return next, nil
+		}
+	}
+	return zero, ErrConflict
+}
+
+// Delete 删除键
+func (s *TypedKV[T]) Delete(ctx context.Context, key string) error {
+	_, err := s.client.Delete(ctx, s.prefix+key)
+	return err
+}
+
+// Watch 监听前缀下的变化直到 ctx 取消，通道随之关闭
+// 首先为现有的每个键发送一次 EventPut；连接中断时从最后收到的版本继续监听，不丢失事件；
+// 所需版本已被压缩时重新读取全部值，对比后补发变化的键的 EventPut 和已删除的键的 EventDelete；
+// 每次全量同步（包括首次）结束后发送一次 EventSync
+func (s *TypedKV[T]) Watch(ctx context.Context) <-chan Event[T] {
+	ch := make(chan Event[T])
+	go s.watch(ctx, ch)
+	return ch
+}
+
+// watch 监听循环
+func (s *TypedKV[T]) watch(ctx context.Context, ch chan<- Event[T]) {
+	defer close(ch)
+
+	known := make(map[string]int64) // 键 -> ModRevision，用于压缩后重新同步时计算差异
+	revision, ok := s.resync(ctx, ch, known)
+	for ok && ctx.Err() == nil {
+		wch := s.client.Watch(clientv3.WithRequireLeader(ctx), s.prefix, clientv3.WithPrefix(), clientv3.WithRev(revision+1))
+		for resp := range wch {
+			if err := resp.Err(); err != nil {
+				if resp.CompactRevision != 0 {
+					s.logger.Warn("监听的版本已被压缩，重新同步", "prefix", s.prefix, "revision", revision+1, "compact_revision", resp.CompactRevision)
+					revision, ok = s.resync(ctx, ch, known)
+				} else {
+					s.logger.Warn("监听中断，稍后继续", "prefix", s.prefix, "revision", revision+1, "error", err)
+				}
+				break
+			}
+			for _, ev := range resp.Events {
+				if !s.emit(ctx, ch, known, ev) {
+					return
+				}
+			}
+			revision = resp.Header.Revision
+		}
+		if !ok || ctx.Err() != nil {
+			return
+		}
+
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// emit 解码并发送单个 etcd 事件，ctx 取消时返回 false
+func (s *TypedKV[T]) emit(ctx context.Context, ch chan<- Event[T], known map[string]int64, ev *clientv3.Event) bool {
+	key := strings.TrimPrefix(string(ev.Kv.Key), s.prefix)
+	event := Event[T]{Type: EventPut, Key: key, Revision: ev.Kv.ModRevision}
+	if ev.Type == clientv3.EventTypeDelete {
+		event.Type = EventDelete
+		delete(known, key)
+	} else {
+		v, err := s.decode(ev.Kv.Value)
+		if err != nil {
+			s.logger.Warn("忽略无法解码的值", "key", string(ev.Kv.Key), "error", err)
+			return true
+		}
+		event.Value = v
+		known[key] = ev.Kv.ModRevision
+	}
+	return send(ctx, ch, event)
+}
+
+// resync 读取全部值并与已知状态对比，补发差异事件，返回读取时的版本；读取失败时按退避重试直到 ctx 取消
+func (s *TypedKV[T]) resync(ctx context.Context, ch chan<- Event[T], known map[string]int64) (int64, bool) {
+	backoff := time.Second
+	for {
+		resp, err := s.client.Get(ctx, s.prefix, clientv3.WithPrefix())
+		if err == nil {
+			seen := make(map[string]bool, len(resp.Kvs))
+			for _, item := range resp.Kvs {
+				key := strings.TrimPrefix(string(item.Key), s.prefix)
+				seen[key] = true
+				if known[key] == item.ModRevision {
+					continue
+				}
+				if !s.emit(ctx, ch, known, &clientv3.Event{Type: clientv3.EventTypePut, Kv: item}) {
+					return 0, false
+				}
+			}
+			for key := range known {
+				if seen[key] {
+					continue
+				}
+				delete(known, key)
+				if !send(ctx, ch, Event[T]{Type: EventDelete, Key: key, Revision: resp.Header.Revision}) {
+					return 0, false
+				}
+			}
+			if !send(ctx, ch, Event[T]{Type: EventSync, Revision: resp.Header.Revision}) {
+				return 0, false
+			}
+			return resp.Header.Revision, true
+		}
+
+		if ctx.Err() != nil {
+			return 0, false
+		}
+		s.logger.Warn("读取监听前缀失败，稍后重试", "prefix", s.prefix, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return 0, false
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// decode 解码值
+func (s *TypedKV[T]) decode(data []byte) (T, error) {
+	var v T
+	err := s.codec.Unmarshal(data, &v)
+	return v, err
+}
+
+// send 发送事件，ctx 取消时返回 false
+func send[T any](ctx context.Context, ch chan<- Event[T], event Event[T]) bool {
+	select {
+	case ch <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
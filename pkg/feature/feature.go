@@ -0,0 +1,195 @@
+package feature
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/prometheus"
+)
+
+// FeatureConfig 功能开关配置
+type FeatureConfig struct {
+	Metrics *prometheus.Metrics // 曝光和更新指标，为空时不记录
+}
+
+// Client 功能开关客户端，同时是应用组件
+// Start 时加载全部开关并在后台监听存储变化，求值只读取内存中的开关，不访问存储
+type Client struct {
+	store  Store
+	config *FeatureConfig
+	logger *zhlog.Helper
+
+	mu    sync.RWMutex
+	flags map[string]*Flag
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// New 创建功能开关客户端
+func New(store Store, config *FeatureConfig, logger *zhlog.Helper) *Client {
+	if config == nil {
+		config = &FeatureConfig{}
+	}
+	return &Client{store: store, config: config, logger: logger, flags: make(map[string]*Flag)}
+}
+
+// Name 组件名称
+func (c *Client) Name() string {
+	return "feature"
+}
+
+// Start 加载全部开关并开始监听变化
+func (c *Client) Start(ctx context.Context) error {
+	flags, err := c.store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	c.apply(flags)
+
+	watchCtx, stop := context.WithCancel(context.Background())
+	c.stop = stop
+	c.done = make(chan struct{})
+	go c.watch(watchCtx)
+
+	c.logger.Info("功能开关已加载", "count", len(flags))
+	return nil
+}
+
+// Stop 停止监听
+func (c *Client) Stop(ctx context.Context) error {
+	if c.stop == nil {
+		return nil
+	}
+	c.stop()
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// watch 监听存储变化，Watch 异常返回时稍后重新加载并继续监听
+func (c *Client) watch(ctx context.Context) {
+	defer close(c.done)
+
+	for ctx.Err() == nil {
+		if err := c.store.Watch(ctx, c.apply); err != nil && ctx.Err() == nil {
+			c.logger.Warn("功能开关监听中断", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return
+		}
+		flags, err := c.store.Load(ctx)
+		if err != nil {
+			c.logger.Warn("重新加载功能开关失败", "error", err)
+			continue
+		}
+		c.apply(flags)
+	}
+}
+
+// apply 替换内存中的开关，校验失败的开关保留旧值
+func (c *Client) apply(flags map[string]*Flag) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	next := make(map[string]*Flag, len(flags))
+	for key, flag := range flags {
+		if err := flag.Validate(); err != nil {
+			c.logger.Warn("忽略无效的功能开关", "key", key, "error", err)
+			if old, ok := c.flags[key]; ok {
+				next[key] = old
+			}
+			continue
+		}
+		next[key] = flag
+	}
+
+	var changed []string
+	for key, flag := range next {
+		if old, ok := c.flags[key]; !ok || !reflect.DeepEqual(old, flag) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range c.flags {
+		if _, ok := next[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	c.flags = next
+
+	for _, key := range changed {
+		c.logger.Info("功能开关已更新", "key", key, "exists", next[key] != nil)
+		if c.config.Metrics != nil {
+			c.config.Metrics.RecordFeatureUpdate(key, len(next))
+		}
+	}
+}
+
+// Flag 返回开关的当前配置，调用方不应修改返回值
+func (c *Client) Flag(key string) (*Flag, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	flag, ok := c.flags[key]
+	return flag, ok
+}
+
+// Flags 返回全部开关的当前配置，调用方不应修改返回值
+func (c *Client) Flags() map[string]*Flag {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return copyFlags(c.flags)
+}
+
+// Evaluate 求值并记录曝光，开关不存在时返回 false
+func (c *Client) Evaluate(key string, ec *EvalContext) Evaluation {
+	result := c.evaluate(key, ec)
+	c.expose(result)
+	return result
+}
+
+// IsEnabled 求值并记录曝光，返回开关是否开启
+func (c *Client) IsEnabled(key string, ec *EvalContext) bool {
+	return c.Evaluate(key, ec).Enabled
+}
+
+// evaluate 求值，不记录曝光
+func (c *Client) evaluate(key string, ec *EvalContext) Evaluation {
+	flag, ok := c.Flag(key)
+	if !ok {
+		return Evaluation{Key: key, Reason: ReasonNotFound}
+	}
+	if ec == nil {
+		ec = &EvalContext{}
+	}
+	return flag.evaluate(ec)
+}
+
+// expose 记录曝光指标
+func (c *Client) expose(result Evaluation) {
+	if c.config.Metrics != nil {
+		c.config.Metrics.RecordFeatureExposure(result.Key, result.Enabled, result.Reason)
+	}
+}
+
+// Save 校验并保存开关，各实例通过监听收到更新
+func (c *Client) Save(ctx context.Context, flag *Flag) error {
+	if err := flag.Validate(); err != nil {
+		return err
+	}
+	return c.store.Save(ctx, flag)
+}
+
+// Delete 删除开关
+func (c *Client) Delete(ctx context.Context, key string) error {
+	return c.store.Delete(ctx, key)
+}
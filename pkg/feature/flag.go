@@ -0,0 +1,184 @@
+package feature
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+)
+
+// 规则匹配的属性名称，其他名称从 EvalContext.Attributes 中读取
+const (
+	AttrUserID       = "user_id"
+	AttrTenant       = "tenant"
+	AttrHeaderPrefix = "header:" // 如 header:X-Beta-Tester
+)
+
+// 规则运算符
+const (
+	OpIn    = "in"     // 属性值在 Values 中（默认）
+	OpNotIn = "not_in" // 属性值不在 Values 中
+)
+
+// 判定依据，用于曝光指标和排查
+const (
+	ReasonNotFound = "not_found" // 开关不存在，返回 false
+	ReasonDisabled = "disabled"  // 总开关关闭
+	ReasonRule     = "rule"      // 命中规则
+	ReasonRollout  = "rollout"   // 按百分比放量
+	ReasonDefault  = "default"   // 总开关开启且未配置放量比例
+)
+
+// Flag 功能开关
+// 总开关关闭时始终返回 false；开启时按顺序匹配规则，命中第一条规则即返回其结果，
+// 未命中任何规则时按 Rollout 百分比放量，未配置 Rollout 时返回 true
+type Flag struct {
+	Key         string   `json:"key"`                   // 开关名称
+	Description string   `json:"description,omitempty"` // 说明
+	Enabled     bool     `json:"enabled"`               // 总开关
+	Rollout     *float64 `json:"rollout,omitempty"`     // 放量百分比 0-100，为空表示全量
+	BucketBy    string   `json:"bucket_by,omitempty"`   // 分桶属性，默认 user_id；属性为空时不放量
+	Salt        string   `json:"salt,omitempty"`        // 分桶盐值，默认使用 Key，修改后所有用户重新分桶
+	Rules       []Rule   `json:"rules,omitempty"`       // 定向规则，按顺序匹配
+}
+
+// Rule 定向规则
+type Rule struct {
+	Attribute string   `json:"attribute"`          // 属性：user_id、tenant、header:<名称> 或自定义属性
+	Operator  string   `json:"operator,omitempty"` // in 或 not_in，默认 in
+	Values    []string `json:"values"`             // 属性取值
+	Serve     bool     `json:"serve"`              // 命中时的结果
+	Rollout   *float64 `json:"rollout,omitempty"`  // 命中且 Serve 为 true 时的放量百分比，为空表示全量
+}
+
+// Validate 校验开关配置
+func (f *Flag) Validate() error {
+	if f.Key == "" {
+		return fmt.Errorf("feature: flag key is empty")
+	}
+	if err := validRollout(f.Rollout); err != nil {
+		return fmt.Errorf("feature: flag %s: %w", f.Key, err)
+	}
+	for i, rule := range f.Rules {
+		if rule.Attribute == "" {
+			return fmt.Errorf("feature: flag %s rule %d: attribute is empty", f.Key, i)
+		}
+		if rule.Operator != "" && rule.Operator != OpIn && rule.Operator != OpNotIn {
+			return fmt.Errorf("feature: flag %s rule %d: unknown operator %q", f.Key, i, rule.Operator)
+		}
+		if err := validRollout(rule.Rollout); err != nil {
+			return fmt.Errorf("feature: flag %s rule %d: %w", f.Key, i, err)
+		}
+	}
+	return nil
+}
+
+// validRollout 校验放量百分比
+func validRollout(rollout *float64) error {
+	if rollout != nil && (*rollout < 0 || *rollout > 100) {
+		return fmt.Errorf("rollout %v out of range [0, 100]", *rollout)
+	}
+	return nil
+}
+
+// EvalContext 求值上下文
+type EvalContext struct {
+	UserID     string            // 用户ID
+	Tenant     string            // 租户ID
+	Headers    http.Header       // 请求头
+	Attributes map[string]string // 自定义属性
+}
+
+// Get 返回属性值
+func (e *EvalContext) Get(attribute string) string {
+	switch {
+	case attribute == AttrUserID:
+		return e.UserID
+	case attribute == AttrTenant:
+		return e.Tenant
+	case strings.HasPrefix(attribute, AttrHeaderPrefix):
+		return e.Headers.Get(strings.TrimPrefix(attribute, AttrHeaderPrefix))
+	default:
+		return e.Attributes[attribute]
+	}
+}
+
+// Evaluation 求值结果
+type Evaluation struct {
+	Key     string `json:"key"`
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
+}
+
+// evaluate 对求值上下文计算开关结果
+func (f *Flag) evaluate(ec *EvalContext) Evaluation {
+	if !f.Enabled {
+		return Evaluation{Key: f.Key, Reason: ReasonDisabled}
+	}
+
+	for _, rule := range f.Rules {
+		if !rule.matches(ec) {
+			continue
+		}
+		enabled := rule.Serve
+		if enabled && rule.Rollout != nil {
+			enabled = f.inRollout(ec, *rule.Rollout)
+		}
+		return Evaluation{Key: f.Key, Enabled: enabled, Reason: ReasonRule}
+	}
+
+	if f.Rollout == nil {
+		return Evaluation{Key: f.Key, Enabled: true, Reason: ReasonDefault}
+	}
+	return Evaluation{Key: f.Key, Enabled: f.inRollout(ec, *f.Rollout), Reason: ReasonRollout}
+}
+
+// matches 判断规则是否命中
+func (r *Rule) matches(ec *EvalContext) bool {
+	value := ec.Get(r.Attribute)
+	found := false
+	if value != "" {
+		for _, v := range r.Values {
+			if v == value {
+				found = true
+				break
+			}
+		}
+	}
+	if r.Operator == OpNotIn {
+		return value != "" && !found
+	}
+	return found
+}
+
+// inRollout 按分桶属性的哈希值判断是否落在放量比例内，同一属性值的结果稳定，扩大比例时已放量的用户保持开启
+func (f *Flag) inRollout(ec *EvalContext, rollout float64) bool {
+	if rollout >= 100 {
+		return true
+	}
+	if rollout <= 0 {
+		return false
+	}
+	attribute := f.BucketBy
+	if attribute == "" {
+		attribute = AttrUserID
+	}
+	value := ec.Get(attribute)
+	if value == "" {
+		return false
+	}
+	salt := f.Salt
+	if salt == "" {
+		salt = f.Key
+	}
+	return float64(bucket(salt, value))/100 < rollout
+}
+
+// bucket 将值映射到 [0, 10000) 的桶，精度为 0.01%
+func bucket(salt, value string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(salt))
+	_, _ = h.Write([]byte{'.'})
+	_, _ = h.Write([]byte(value))
+	return h.Sum64() % 10000
+}
@@ -0,0 +1,133 @@
+package feature
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"go-template/utils/common"
+)
+
+// contextKey 请求求值器在 gin.Context 中的键
+const contextKey = "feature_flags"
+
+// GinConfig Gin 中间件配置
+type GinConfig struct {
+	UserIDKey    string // 用户ID在 gin.Context 中的键（由认证中间件写入），默认 user_id
+	UserIDHeader string // gin.Context 中没有用户ID时读取的请求头，如 X-User-ID；默认不读取，仅在请求头由可信网关写入时配置
+	TenantKey    string // 租户ID在 gin.Context 中的键，默认 tenant_id
+	TenantHeader string // gin.Context 中没有租户ID时读取的请求头，如 X-Tenant-ID；默认不读取，仅在请求头由可信网关写入时配置
+}
+
+// requestEvaluator 单个请求的求值器，同一请求内同一开关只求值一次，结果保持一致且只记录一次曝光
+type requestEvaluator struct {
+	client *Client
+	config *GinConfig
+	c      *gin.Context
+
+	mu      sync.Mutex
+	ec      *EvalContext
+	results map[string]Evaluation
+}
+
+// Middleware 返回在 gin.Context 中注入请求求值器的中间件
+// 求值上下文在第一次求值时才构建，因此认证中间件注册在其后也能读取到用户ID
+func (c *Client) Middleware(config *GinConfig) gin.HandlerFunc {
+	if config == nil {
+		config = &GinConfig{}
+	}
+	if config.UserIDKey == "" {
+		config.UserIDKey = "user_id"
+	}
+	if config.TenantKey == "" {
+		config.TenantKey = "tenant_id"
+	}
+
+	return func(ctx *gin.Context) {
+		ctx.Set(contextKey, &requestEvaluator{client: c, config: config, c: ctx})
+		ctx.Next()
+	}
+}
+
+// Evaluate 在当前请求中求值，未使用 Middleware 时返回 not_found
+func Evaluate(c *gin.Context, key string) Evaluation {
+	v, ok := c.Get(contextKey)
+	if !ok {
+		return Evaluation{Key: key, Reason: ReasonNotFound}
+	}
+	return v.(*requestEvaluator).evaluate(key)
+}
+
+// Enabled 返回开关在当前请求中是否开启
+func Enabled(c *gin.Context, key string) bool {
+	return Evaluate(c, key).Enabled
+}
+
+// SetAttribute 设置当前请求的自定义属性，用于匹配自定义属性规则；需在第一次求值前调用
+func SetAttribute(c *gin.Context, name, value string) {
+	v, ok := c.Get(contextKey)
+	if !ok {
+		return
+	}
+	r := v.(*requestEvaluator)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ec := r.evalContext()
+	if ec.Attributes == nil {
+		ec.Attributes = make(map[string]string)
+	}
+	ec.Attributes[name] = value
+}
+
+// Require 返回开关关闭时以资源不存在响应的中间件，用于灰度中的接口
+func Require(key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Enabled(c, key) {
+			common.BusinessResponse(c, common.CodeNotFound, nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// evaluate 求值并缓存结果，首次求值时记录曝光
+func (r *requestEvaluator) evaluate(key string) Evaluation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if result, ok := r.results[key]; ok {
+		return result
+	}
+	result := r.client.evaluate(key, r.evalContext())
+	r.client.expose(result)
+	if r.results == nil {
+		r.results = make(map[string]Evaluation)
+	}
+	r.results[key] = result
+	return result
+}
+
+// evalContext 构建求值上下文，调用方需持有 mu
+func (r *requestEvaluator) evalContext() *EvalContext {
+	if r.ec == nil {
+		r.ec = &EvalContext{
+			UserID:  r.value(r.config.UserIDKey, r.config.UserIDHeader),
+			Tenant:  r.value(r.config.TenantKey, r.config.TenantHeader),
+			Headers: r.c.Request.Header,
+		}
+	}
+	return r.ec
+}
+
+// value 优先读取 gin.Context 中的值，不存在且配置了请求头时读取请求头
+func (r *requestEvaluator) value(key, header string) string {
+	if v, ok := r.c.Get(key); ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	if header == "" {
+		return ""
+	}
+	return r.c.GetHeader(header)
+}
@@ -0,0 +1,199 @@
+package feature
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/etcd"
+)
+
+// Store 功能开关存储
+type Store interface {
+	// Load 读取全部开关
+	Load(ctx context.Context) (map[string]*Flag, error)
+	// Watch 监听开关变化直到 ctx 取消，每次变化后以全部开关调用 fn
+	Watch(ctx context.Context, fn func(flags map[string]*Flag)) error
+	// Save 保存开关
+	Save(ctx context.Context, flag *Flag) error
+	// Delete 删除开关
+	Delete(ctx context.Context, key string) error
+}
+
+// EtcdStore 基于 etcd 的开关存储，每个开关以 JSON 保存在 <prefix><key>
+type EtcdStore struct {
+	kv *etcd.TypedKV[Flag]
+}
+
+// NewEtcdStore 创建 etcd 开关存储，prefix 默认 /feature-flags/
+func NewEtcdStore(client *clientv3.Client, prefix string, logger *zhlog.Helper) *EtcdStore {
+	if prefix == "" {
+		prefix = "/feature-flags/"
+	}
+	return &EtcdStore{kv: etcd.NewTypedKV[Flag](client, prefix, nil, logger)}
+}
+
+// Load 读取全部开关
+func (s *EtcdStore) Load(ctx context.Context) (map[string]*Flag, error) {
+	values, _, err := s.kv.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	flags := make(map[string]*Flag, len(values))
+	for key, flag := range values {
+		flag.Key = key
+		flags[key] = &flag
+	}
+	return flags, nil
+}
+
+// Watch 监听前缀，连接中断和版本压缩由 etcd.TypedKV 处理
+// 首次全量同步完成前不调用 fn，避免以不完整的开关集合替换 Load 读取的结果
+func (s *EtcdStore) Watch(ctx context.Context, fn func(flags map[string]*Flag)) error {
+	flags := make(map[string]*Flag)
+	synced := false
+	for ev := range s.kv.Watch(ctx) {
+		switch ev.Type {
+		case etcd.EventSync:
+			synced = true
+		case etcd.EventDelete:
+			delete(flags, ev.Key)
+		default:
+			flag := ev.Value
+			flag.Key = ev.Key
+			flags[ev.Key] = &flag
+		}
+		if synced {
+			fn(copyFlags(flags))
+		}
+	}
+	return ctx.Err()
+}
+
+// Save 保存开关
+func (s *EtcdStore) Save(ctx context.Context, flag *Flag) error {
+	_, err := s.kv.Put(ctx, flag.Key, *flag)
+	return err
+}
+
+// Delete 删除开关
+func (s *EtcdStore) Delete(ctx context.Context, key string) error {
+	return s.kv.Delete(ctx, key)
+}
+
+// RedisStoreConfig Redis 开关存储配置
+type RedisStoreConfig struct {
+	Key            string        // 保存开关的哈希表，默认 feature:flags
+	Channel        string        // 变更通知频道，默认 feature:flags:changed
+	ReloadInterval time.Duration // 定期全量重新读取的间隔，弥补 Pub/Sub 断线期间丢失的通知，默认 30s
+}
+
+// RedisStore 基于 Redis 的开关存储，开关以 JSON 保存在哈希表中，修改后通过 Pub/Sub 通知各实例重新读取
+type RedisStore struct {
+	rdb    *redis.Client
+	config *RedisStoreConfig
+	logger *zhlog.Helper
+}
+
+// NewRedisStore 创建 Redis 开关存储
+func NewRedisStore(rdb *redis.Client, config *RedisStoreConfig, logger *zhlog.Helper) *RedisStore {
+	if config.Key == "" {
+		config.Key = "feature:flags"
+	}
+	if config.Channel == "" {
+		config.Channel = config.Key + ":changed"
+	}
+	if config.ReloadInterval <= 0 {
+		config.ReloadInterval = 30 * time.Second
+	}
+	return &RedisStore{rdb: rdb, config: config, logger: logger}
+}
+
+// Load 读取全部开关，无法解析的开关记录警告后跳过
+func (s *RedisStore) Load(ctx context.Context) (map[string]*Flag, error) {
+	values, err := s.rdb.HGetAll(ctx, s.config.Key).Result()
+	if err != nil {
+		return nil, err
+	}
+	flags := make(map[string]*Flag, len(values))
+	for key, raw := range values {
+		flag := &Flag{}
+		if err := json.Unmarshal([]byte(raw), flag); err != nil {
+			s.logger.Warn("忽略无法解析的功能开关", "key", key, "error", err)
+			continue
+		}
+		flag.Key = key
+		flags[key] = flag
+	}
+	return flags, nil
+}
+
+// Watch 收到变更通知或到达重新读取间隔时读取全部开关
+func (s *RedisStore) Watch(ctx context.Context, fn func(flags map[string]*Flag)) error {
+	sub := s.rdb.Subscribe(ctx, s.config.Channel)
+	defer sub.Close()
+	messages := sub.Channel()
+
+	ticker := time.NewTicker(s.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case _, ok := <-messages:
+			if !ok {
+				return ctx.Err()
+			}
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		flags, err := s.Load(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			s.logger.Warn("读取功能开关失败", "key", s.config.Key, "error", err)
+			continue
+		}
+		fn(flags)
+	}
+}
+
+// Save 保存开关并通知各实例
+func (s *RedisStore) Save(ctx context.Context, flag *Flag) error {
+	data, err := json.Marshal(flag)
+	if err != nil {
+		return err
+	}
+	_, err = s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, s.config.Key, flag.Key, data)
+		pipe.Publish(ctx, s.config.Channel, flag.Key)
+		return nil
+	})
+	return err
+}
+
+// Delete 删除开关并通知各实例
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	_, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, s.config.Key, key)
+		pipe.Publish(ctx, s.config.Channel, key)
+		return nil
+	})
+	return err
+}
+
+// copyFlags 复制开关集合
+func copyFlags(flags map[string]*Flag) map[string]*Flag {
+	out := make(map[string]*Flag, len(flags))
+	for k, v := range flags {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// grpcWatchInterval Watch 重新检查状态的间隔
+const grpcWatchInterval = time.Second
+
+// GRPCHealthServer 返回基于注册中心的标准 gRPC 健康检查服务 (grpc.health.v1.Health)
+// 服务名为空时返回整体状态，与就绪探针一致；服务名为检查项名称时返回该检查项的状态
+func (r *Registry) GRPCHealthServer() healthpb.HealthServer {
+	return &grpcHealthServer{registry: r}
+}
+
+// grpcHealthServer gRPC 健康检查服务
+type grpcHealthServer struct {
+	healthpb.UnimplementedHealthServer
+	registry *Registry
+}
+
+// Check 返回服务当前状态，未注册的服务名返回 NotFound
+func (s *grpcHealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	st, ok := s.registry.servingStatus(ctx, req.GetService())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+	return &healthpb.HealthCheckResponse{Status: st}, nil
+}
+
+// List 返回所有检查项的当前状态，空字符串对应整体状态
+func (s *grpcHealthServer) List(ctx context.Context, _ *healthpb.HealthListRequest) (*healthpb.HealthListResponse, error) {
+	names := s.registry.names()
+	statuses := make(map[string]*healthpb.HealthCheckResponse, len(names)+1)
+	for _, name := range append(names, "") {
+		st, _ := s.registry.servingStatus(ctx, name)
+		statuses[name] = &healthpb.HealthCheckResponse{Status: st}
+	}
+	return &healthpb.HealthListResponse{Statuses: statuses}, nil
+}
+
+// Watch 先发送当前状态，之后在状态变化时推送，未注册的服务名返回 SERVICE_UNKNOWN
+func (s *grpcHealthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ctx := stream.Context()
+	ticker := time.NewTicker(grpcWatchInterval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_ServingStatus(-1)
+	for {
+		st, ok := s.registry.servingStatus(ctx, req.GetService())
+		if !ok {
+			st = healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		}
+		if st != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last = st
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// servingStatus 返回服务的 gRPC 状态，排空阶段一律返回 NOT_SERVING
+func (r *Registry) servingStatus(ctx context.Context, service string) (healthpb.HealthCheckResponse_ServingStatus, bool) {
+	if service == "" {
+		if r.IsDraining() || r.Check(ctx).Status == StatusFail {
+			return healthpb.HealthCheckResponse_NOT_SERVING, true
+		}
+		return healthpb.HealthCheckResponse_SERVING, true
+	}
+
+	r.mu.RLock()
+	e, ok := r.entries[service]
+	r.mu.RUnlock()
+	if !ok {
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, false
+	}
+
+	if r.IsDraining() || r.run(ctx, e).Status != StatusOK {
+		return healthpb.HealthCheckResponse_NOT_SERVING, true
+	}
+	return healthpb.HealthCheckResponse_SERVING, true
+}
+
+// names 返回所有检查项名称
+func (r *Registry) names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	return names
+}
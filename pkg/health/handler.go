@@ -0,0 +1,92 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册 Kubernetes 风格的探针端点: /livez、/readyz、/healthz
+func (r *Registry) RegisterRoutes(router gin.IRoutes) {
+	router.GET("/livez", r.LivezHandler())
+	router.GET("/readyz", r.ReadyzHandler())
+	router.GET("/healthz", r.HealthzHandler())
+}
+
+// LivezHandler 存活探针，只反映进程本身是否存活，不检查外部依赖
+// 避免依赖故障导致 Kubernetes 重启所有实例
+func (r *Registry) LivezHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		results := []Result{{Name: "ping", Status: StatusOK}}
+		writeText(c, "livez", http.StatusOK, results)
+	}
+}
+
+// ReadyzHandler 就绪探针，关键依赖失败或服务处于排空阶段时返回 503
+// 返回JSON格式的逐项检查结果，支持 ?exclude=redis 跳过指定检查
+func (r *Registry) ReadyzHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if r.IsDraining() {
+			c.JSON(http.StatusServiceUnavailable, &Report{
+				Status: StatusFail,
+				Checks: []Result{{Name: "shutdown", Status: StatusFail, Critical: true, Error: ErrShuttingDown.Error()}},
+			})
+			return
+		}
+
+		report := r.Check(c.Request.Context(), c.QueryArray("exclude")...)
+		c.JSON(report.HTTPStatus(), report)
+	}
+}
+
+// HealthzHandler 综合健康检查，输出 Kubernetes 文本格式
+// 默认成功时只返回 "ok"，失败或带 ?verbose 参数时逐项列出检查结果
+func (r *Registry) HealthzHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := r.Check(c.Request.Context(), c.QueryArray("exclude")...)
+		results := report.Checks
+		if r.IsDraining() {
+			results = append(results, Result{Name: "shutdown", Status: StatusFail, Critical: true, Error: ErrShuttingDown.Error()})
+		}
+
+		status := report.HTTPStatus()
+		if r.IsDraining() {
+			status = http.StatusServiceUnavailable
+		}
+		writeText(c, "healthz", status, results)
+	}
+}
+
+// HTTPStatus 报告对应的HTTP状态码，非关键依赖失败不影响可用性
+func (rp *Report) HTTPStatus() int {
+	if rp.Status == StatusFail {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+// writeText 以 Kubernetes 探针格式输出检查结果
+func writeText(c *gin.Context, probe string, status int, results []Result) {
+	_, verbose := c.GetQuery("verbose")
+	if status == http.StatusOK && !verbose {
+		c.String(status, "ok")
+		return
+	}
+
+	var b strings.Builder
+	for _, result := range results {
+		if result.Status == StatusOK {
+			fmt.Fprintf(&b, "[+]%s ok\n", result.Name)
+		} else {
+			fmt.Fprintf(&b, "[-]%s failed: %s\n", result.Name, result.Error)
+		}
+	}
+	if status == http.StatusOK {
+		fmt.Fprintf(&b, "%s check passed\n", probe)
+	} else {
+		fmt.Fprintf(&b, "%s check failed\n", probe)
+	}
+	c.String(status, b.String())
+}
@@ -0,0 +1,275 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+)
+
+// 检查状态
+const (
+	StatusOK       = "ok"       // 所有检查通过
+	StatusDegraded = "degraded" // 非关键依赖检查失败
+	StatusFail     = "fail"     // 关键依赖检查失败
+)
+
+// ErrShuttingDown 服务正在关闭
+var ErrShuttingDown = errors.New("service is shutting down")
+
+// Checker 依赖检查器
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc 函数形式的检查器
+type CheckerFunc func(ctx context.Context) error
+
+// Check 执行检查
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Check 健康检查项
+type Check struct {
+	Name     string        // 检查名称，如 mysql、redis
+	Checker  Checker       // 检查器
+	Timeout  time.Duration // 单次检查超时时间，为0时使用默认值
+	Critical bool          // 是否关键依赖，关键依赖失败时服务不可用
+}
+
+// Result 单项检查结果
+type Result struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report 健康检查报告
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// HealthConfig 健康检查配置
+type HealthConfig struct {
+	Namespace      string        // Prometheus命名空间
+	Subsystem      string        // Prometheus子系统
+	CacheTTL       time.Duration // 检查结果缓存时间，默认 5s
+	DefaultTimeout time.Duration // 默认检查超时时间，默认 3s
+}
+
+// entry 已注册的检查项及其缓存结果
+type entry struct {
+	check  Check
+	mu     sync.Mutex // 保证同一检查同时只有一个在执行
+	result Result
+	err    error
+}
+
+// Registry 健康检查注册中心
+type Registry struct {
+	mu       sync.RWMutex
+	entries  map[string]*entry
+	draining atomic.Bool
+	status   *prometheus.GaugeVec
+	duration *prometheus.GaugeVec
+	config   *HealthConfig
+	logger   *zhlog.Helper
+}
+
+// NewRegistry 创建健康检查注册中心
+func NewRegistry(config *HealthConfig, logger *zhlog.Helper) *Registry {
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = 5 * time.Second
+	}
+	if config.DefaultTimeout <= 0 {
+		config.DefaultTimeout = 3 * time.Second
+	}
+
+	return &Registry{
+		entries: make(map[string]*entry),
+		status: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: config.Namespace,
+				Subsystem: config.Subsystem,
+				Name:      "health_check_status",
+				Help:      "Result of the last health check (1 = healthy, 0 = unhealthy).",
+			},
+			[]string{"check", "critical"},
+		),
+		duration: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: config.Namespace,
+				Subsystem: config.Subsystem,
+				Name:      "health_check_duration_seconds",
+				Help:      "Duration of the last health check in seconds.",
+			},
+			[]string{"check"},
+		),
+		config: config,
+		logger: logger,
+	}
+}
+
+// Register 注册检查项，同名检查项会被覆盖
+func (r *Registry) Register(checks ...Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, check := range checks {
+		if check.Timeout <= 0 {
+			check.Timeout = r.config.DefaultTimeout
+		}
+		r.entries[check.Name] = &entry{check: check}
+		r.logger.Info("注册健康检查", "check", check.Name, "critical", check.Critical, "timeout", check.Timeout)
+	}
+}
+
+// Collectors 返回健康检查相关的Prometheus指标，供 Metrics.RegisterMetrics 注册
+func (r *Registry) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{r.status, r.duration}
+}
+
+// SetDraining 标记服务进入排空阶段，此后就绪检查将失败
+func (r *Registry) SetDraining(draining bool) {
+	r.draining.Store(draining)
+}
+
+// IsDraining 服务是否处于排空阶段
+func (r *Registry) IsDraining() bool {
+	return r.draining.Load()
+}
+
+// Check 执行所有检查项 (使用缓存结果)，exclude 中的检查项将被跳过
+func (r *Registry) Check(ctx context.Context, exclude ...string) *Report {
+	r.mu.RLock()
+	entries := make([]*entry, 0, len(r.entries))
+	for name, e := range r.entries {
+		if contains(exclude, name) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].check.Name < entries[j].check.Name
+	})
+
+	report := &Report{
+		Status: StatusOK,
+		Checks: make([]Result, len(entries)),
+	}
+
+	// 并行执行各检查项
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			report.Checks[i] = r.run(ctx, e)
+		}(i, e)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status == StatusOK {
+			continue
+		}
+		if result.Critical {
+			report.Status = StatusFail
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+// run 执行单个检查项，在缓存有效期内直接返回上次结果
+func (r *Registry) run(ctx context.Context, e *entry) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.result.CheckedAt.IsZero() && time.Since(e.result.CheckedAt) < r.config.CacheTTL {
+		return e.result
+	}
+
+	// 检查结果会被多个请求共享，不受单个请求取消的影响
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := safeCheck(checkCtx, e.check.Checker)
+	elapsed := time.Since(start)
+
+	result := Result{
+		Name:      e.check.Name,
+		Status:    StatusOK,
+		Critical:  e.check.Critical,
+		Duration:  elapsed.String(),
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+		if e.err == nil {
+			r.logger.Warn("健康检查失败", "check", e.check.Name, "critical", e.check.Critical, "error", err)
+		}
+	} else if e.err != nil {
+		r.logger.Info("健康检查恢复", "check", e.check.Name)
+	}
+
+	e.result = result
+	e.err = err
+
+	value := 1.0
+	if err != nil {
+		value = 0
+	}
+	r.status.WithLabelValues(e.check.Name, fmt.Sprint(e.check.Critical)).Set(value)
+	r.duration.WithLabelValues(e.check.Name).Set(elapsed.Seconds())
+
+	return result
+}
+
+// safeCheck 执行检查，超时后立即返回，并将panic转换为错误
+func safeCheck(ctx context.Context, checker Checker) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("health check panic: %v", p)
+			}
+		}()
+		done <- checker.Check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("health check timed out: %w", ctx.Err())
+	}
+}
+
+// contains 判断切片是否包含指定字符串
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	"go-template/utils/common"
+)
+
+// AuthFunc 认证函数，返回携带认证信息（如用户ID）的 context
+// 返回 *common.Error 时原样返回给客户端，其他错误包装为 CodeUnauthorized
+type AuthFunc func(ctx context.Context, fullMethod string) (context.Context, error)
+
+// AuthConfig 认证配置
+type AuthConfig struct {
+	Authenticate AuthFunc // 认证函数，必需
+	SkipMethod   []string // 不需要认证的方法前缀，默认跳过健康检查和反射服务
+}
+
+// UnaryAuth 认证一元拦截器
+func UnaryAuth(config *AuthConfig) grpc.UnaryServerInterceptor {
+	skip := skipList(config.SkipMethod)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if matchPrefix(skip, info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := authenticate(ctx, config.Authenticate, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuth 认证流式拦截器
+func StreamAuth(config *AuthConfig) grpc.StreamServerInterceptor {
+	skip := skipList(config.SkipMethod)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if matchPrefix(skip, info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		ctx, err := authenticate(ss.Context(), config.Authenticate, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, WrapServerStream(ctx, ss))
+	}
+}
+
+// BearerToken 获取 authorization 元数据中的 Bearer Token
+func BearerToken(ctx context.Context) (string, bool) {
+	auth := incomingValue(ctx, "authorization")
+	scheme, token, ok := strings.Cut(auth, " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// authenticate 执行认证函数并统一错误
+func authenticate(ctx context.Context, fn AuthFunc, method string) (context.Context, error) {
+	authCtx, err := fn(ctx, method)
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			return nil, err
+		}
+		return nil, common.WrapError(err, common.CodeUnauthorized)
+	}
+	if authCtx == nil {
+		authCtx = ctx
+	}
+	return authCtx, nil
+}
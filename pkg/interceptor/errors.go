@@ -0,0 +1,32 @@
+package interceptor
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"go-template/utils/common"
+)
+
+// UnaryErrors 错误转换一元拦截器
+// 处理器返回的错误经 common.ToGRPCStatus 转换为 gRPC 状态：业务状态码映射为 gRPC 状态码，
+// 消息按 accept-language 本地化，详情中携带业务状态码和结构化详情，底层错误不返回给客户端
+func UnaryErrors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, common.ToGRPCStatus(err, Locale(ctx)).Err()
+		}
+		return resp, nil
+	}
+}
+
+// StreamErrors 错误转换流式拦截器
+func StreamErrors() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return common.ToGRPCStatus(err, Locale(ss.Context())).Err()
+		}
+		return nil
+	}
+}
@@ -0,0 +1,103 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"go-template/utils/common"
+)
+
+// RequestIDMetadata 请求ID元数据键，与HTTP的 X-Request-ID 对应
+const RequestIDMetadata = "x-request-id"
+
+// maxRequestIDLength 客户端传入的请求ID最大长度，超出时重新生成
+const maxRequestIDLength = 128
+
+// requestIDKey 请求ID在 context 中的键
+type requestIDKey struct{}
+
+// UnaryRequestID 请求ID一元拦截器
+// 优先使用客户端传入的 x-request-id 元数据，否则生成新的ID，并写入响应头和 context
+func UnaryRequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withRequestID(ctx), req)
+	}
+}
+
+// StreamRequestID 请求ID流式拦截器
+func StreamRequestID() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, WrapServerStream(withRequestID(ss.Context()), ss))
+	}
+}
+
+// GetRequestID 获取当前调用的请求ID，未使用 RequestID 拦截器时返回元数据中的值
+func GetRequestID(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	return incomingValue(ctx, RequestIDMetadata)
+}
+
+// Locale 根据 accept-language 元数据选择当前调用的语言
+func Locale(ctx context.Context) string {
+	if accept := incomingValue(ctx, "accept-language"); accept != "" {
+		return common.DefaultCatalog().Match(accept)
+	}
+	return common.DefaultLocale
+}
+
+// WrapServerStream 返回使用指定 context 的服务端流，用于在流式拦截器中向下游传递 context
+func WrapServerStream(ctx context.Context, ss grpc.ServerStream) grpc.ServerStream {
+	return &wrappedStream{ServerStream: ss, ctx: ctx}
+}
+
+// wrappedStream 替换了 context 的服务端流
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回替换后的 context
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
+
+// withRequestID 确定请求ID并写入响应头和 context
+func withRequestID(ctx context.Context) context.Context {
+	id := incomingValue(ctx, RequestIDMetadata)
+	if id == "" || len(id) > maxRequestIDLength {
+		id = newRequestID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, id))
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// incomingValue 获取调用元数据中键对应的第一个值
+func incomingValue(ctx context.Context, key string) string {
+	values := metadata.ValueFromIncomingContext(ctx, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// peerAddr 返回客户端地址
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// newRequestID 生成32位十六进制随机请求ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,121 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/utils/common"
+)
+
+// LoggingConfig 调用日志配置
+type LoggingConfig struct {
+	Logger     *zhlog.Helper // 日志记录器，通过 pkg/helper 创建
+	SkipMethod []string      // 不记录日志的方法前缀，默认跳过健康检查和反射服务
+}
+
+// defaultSkipMethods 默认不记录日志和不需要认证的方法前缀
+var defaultSkipMethods = []string{"/grpc.health.v1.Health/", "/grpc.reflection."}
+
+// UnaryLogging 调用日志一元拦截器
+// 成功的调用记录为 Debug；客户端错误记录为 Warn，服务端错误记录为 Error，并包含业务状态码和底层错误
+// 应位于 UnaryErrors 之内，以便记录转换前的原始错误
+func UnaryLogging(config *LoggingConfig) grpc.UnaryServerInterceptor {
+	skip := skipList(config.SkipMethod)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if matchPrefix(skip, info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		config.log(ctx, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamLogging 调用日志流式拦截器
+func StreamLogging(config *LoggingConfig) grpc.StreamServerInterceptor {
+	skip := skipList(config.SkipMethod)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if matchPrefix(skip, info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		start := time.Now()
+		err := handler(srv, ss)
+		config.log(ss.Context(), info.FullMethod, start, err)
+		return err
+	}
+}
+
+// log 按调用结果选择日志级别
+func (config *LoggingConfig) log(ctx context.Context, method string, start time.Time, err error) {
+	fields := []interface{}{
+		"method", method,
+		"duration", time.Since(start).String(),
+		"peer", peerAddr(ctx),
+		"request_id", GetRequestID(ctx),
+	}
+
+	if err == nil {
+		config.Logger.Debug(append([]interface{}{"gRPC调用完成", "code", codes.OK.String()}, fields...)...)
+		return
+	}
+
+	var code codes.Code
+	var serverError bool
+	var e *common.Error
+	if st, ok := status.FromError(err); ok && !errors.As(err, &e) {
+		code = st.Code()
+		serverError = isServerCode(code)
+	} else {
+		e = common.FromError(err)
+		code = common.GetGRPCCode(e.Code)
+		serverError = e.HTTPStatus() >= 500
+		fields = append(fields, "business_code", int(e.Code))
+	}
+	fields = append([]interface{}{"gRPC调用失败", "code", code.String()}, fields...)
+	fields = append(fields, "error", err)
+
+	if serverError {
+		config.Logger.Error(fields...)
+	} else {
+		config.Logger.Warn(fields...)
+	}
+}
+
+// isServerCode 判断 gRPC 状态码是否属于服务端错误
+func isServerCode(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented,
+		codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+// skipList 返回需要跳过的方法前缀，未配置时使用默认值
+func skipList(skip []string) []string {
+	if skip == nil {
+		return defaultSkipMethods
+	}
+	return skip
+}
+
+// matchPrefix 判断方法是否匹配任一前缀
+func matchPrefix(prefixes []string, method string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(method, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/prometheus"
+	"go-template/utils/common"
+)
+
+// RecoveryConfig panic恢复配置
+type RecoveryConfig struct {
+	Logger  *zhlog.Helper       // 日志记录器，通过 pkg/helper 创建
+	Metrics *prometheus.Metrics // 可选，用于统计panic次数
+}
+
+// UnaryRecovery panic恢复一元拦截器
+// 记录panic及堆栈并记录到当前span，返回 CodeInternalError，经 UnaryErrors 转换为 Internal 状态
+func UnaryRecovery(config *RecoveryConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = config.recovered(ctx, info.FullMethod, rec)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecovery panic恢复流式拦截器
+func StreamRecovery(config *RecoveryConfig) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = config.recovered(ss.Context(), info.FullMethod, rec)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// recovered 记录panic并返回内部错误
+func (config *RecoveryConfig) recovered(ctx context.Context, method string, rec interface{}) error {
+	err, ok := rec.(error)
+	if !ok {
+		err = fmt.Errorf("%v", rec)
+	}
+
+	stack := string(debug.Stack())
+	config.Logger.Error("gRPC调用处理发生panic",
+		"panic", rec,
+		"method", method,
+		"peer", peerAddr(ctx),
+		"request_id", GetRequestID(ctx),
+		"stack", stack,
+	)
+
+	span := oteltrace.SpanFromContext(ctx)
+	span.RecordError(err, oteltrace.WithAttributes(
+		attribute.String("exception.type", "panic"),
+		attribute.String("exception.stacktrace", stack),
+	))
+	span.SetStatus(codes.Error, "panic recovered")
+
+	if config.Metrics != nil {
+		config.Metrics.RecordGRPCPanic(method)
+	}
+	return common.WrapError(err, common.CodeInternalError)
+}
@@ -0,0 +1,133 @@
+package jaeger
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor 返回gRPC一元调用拦截器用于自动追踪调用
+func (tp *TracingProvider) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if tp.config.Disabled || tp.tracer == nil {
+			return handler(ctx, req)
+		}
+
+		ctx, span := tp.startServerSpan(ctx, info.FullMethod)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		finishServerSpan(span, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor 返回gRPC流式调用拦截器用于自动追踪调用
+func (tp *TracingProvider) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if tp.config.Disabled || tp.tracer == nil {
+			return handler(srv, ss)
+		}
+
+		ctx, span := tp.startServerSpan(ss.Context(), info.FullMethod)
+		defer span.End()
+
+		err := handler(srv, &tracedStream{ServerStream: ss, ctx: ctx})
+		finishServerSpan(span, err)
+		return err
+	}
+}
+
+// startServerSpan 从调用元数据中提取trace context并开始服务端span
+func (tp *TracingProvider) startServerSpan(ctx context.Context, fullMethod string) (context.Context, oteltrace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	service, method := splitFullMethod(fullMethod)
+	return tp.tracer.Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		oteltrace.WithAttributes(
+			semconv.RPCSystemGRPC,
+			semconv.RPCService(service),
+			semconv.RPCMethod(method),
+		),
+		oteltrace.WithSpanKind(oteltrace.SpanKindServer),
+	)
+}
+
+// finishServerSpan 记录gRPC状态码，服务端错误标记为span错误
+func finishServerSpan(span oteltrace.Span, err error) {
+	st, _ := status.FromError(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(st.Code())))
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	if isServerError(st.Code()) {
+		span.SetStatus(codes.Error, st.Message())
+	}
+}
+
+// isServerError 判断gRPC状态码是否属于服务端错误，客户端错误不标记span状态
+func isServerError(code grpccodes.Code) bool {
+	switch code {
+	case grpccodes.Unknown, grpccodes.DeadlineExceeded, grpccodes.Unimplemented,
+		grpccodes.Internal, grpccodes.Unavailable, grpccodes.DataLoss:
+		return true
+	}
+	return false
+}
+
+// splitFullMethod 将 /package.Service/Method 拆分为服务名和方法名
+func splitFullMethod(fullMethod string) (string, string) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return "unknown", "unknown"
+	}
+	return service, method
+}
+
+// tracedStream 携带span上下文的服务端流
+type tracedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回携带span的上下文
+func (s *tracedStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataCarrier 适配 gRPC 元数据的 TextMapCarrier
+type metadataCarrier metadata.MD
+
+// Get 获取键对应的第一个值
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Set 设置键值
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keys 返回所有键
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -18,6 +18,8 @@ import (
 	oteltrace "go.opentelemetry.io/otel/trace"
 
 	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/health"
 )
 
 // JaegerConfig Jaeger配置
@@ -246,6 +248,53 @@ func (tp *TracingProvider) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+// Name 组件名称，TracingProvider 可作为应用组件注册到 app.App
+func (tp *TracingProvider) Name() string {
+	return "tracing"
+}
+
+// Start 启动组件，导出器在创建时已初始化，无需额外操作
+func (tp *TracingProvider) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop 停止组件，刷新并关闭导出器
+func (tp *TracingProvider) Stop(ctx context.Context) error {
+	return tp.Shutdown(ctx)
+}
+
+// HealthCheck 返回链路追踪导出器的健康检查项
+// 检查 Jaeger Collector 是否可达，追踪失败不影响业务，作为非关键依赖
+func (tp *TracingProvider) HealthCheck() health.Check {
+	return health.Check{
+		Name:     "tracing",
+		Timeout:  2 * time.Second,
+		Critical: false,
+		Checker: health.CheckerFunc(func(ctx context.Context) error {
+			if tp.config.Disabled || tp.provider == nil {
+				return nil
+			}
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, tp.config.JaegerURL, nil)
+			if err != nil {
+				return err
+			}
+
+			// Collector 对 GET 请求通常返回 405，只要能得到HTTP响应即认为可达
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return fmt.Errorf("jaeger collector unreachable: %w", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode >= http.StatusInternalServerError {
+				return fmt.Errorf("jaeger collector returned status %d", resp.StatusCode)
+			}
+			return nil
+		}),
+	}
+}
+
 // DefaultConfig 返回默认配置
 func DefaultConfig(serviceName string) *JaegerConfig {
 	return &JaegerConfig{
@@ -0,0 +1,303 @@
+package job
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"go-template/pkg/prometheus"
+	"go-template/utils/common"
+)
+
+// DefaultQueue 默认队列名称
+const DefaultQueue = "default"
+
+// JobConfig 后台任务队列配置，Client 和 Worker 需使用相同的 Prefix
+type JobConfig struct {
+	Prefix            string              // Redis 键前缀，默认 job:
+	Group             string              // 消费者组名称，默认 workers
+	Consumer          string              // 消费者名称，默认 主机名-进程号
+	Queues            []QueueConfig       // Worker 消费的队列，默认只消费 default 队列
+	MaxRetries        int                 // 默认最大重试次数，默认 5
+	InitialBackoff    time.Duration       // 首次重试等待时间，默认 1s
+	MaxBackoff        time.Duration       // 最大重试等待时间，默认 10m
+	VisibilityTimeout time.Duration       // 可见性超时，超时未确认的任务会被其他消费者领取，处理器的超时时间为其 90%（从领取时起算），默认 5m
+	ClaimInterval     time.Duration       // 检查超时任务的间隔，默认 30s
+	PollInterval      time.Duration       // 检查到期延迟任务的间隔，默认 1s
+	BlockTimeout      time.Duration       // XREADGROUP 阻塞等待时间，默认 5s
+	MaxLen            int64               // 队列流的近似最大长度，0 表示不限制
+	Metrics           *prometheus.Metrics // 任务指标，可为 nil
+}
+
+// QueueConfig 队列配置
+type QueueConfig struct {
+	Name        string // 队列名称
+	Concurrency int    // 并发处理数，默认 10
+}
+
+// setDefaults 填充默认配置
+func (c *JobConfig) setDefaults() {
+	if c.Prefix == "" {
+		c.Prefix = "job:"
+	}
+	if c.Group == "" {
+		c.Group = "workers"
+	}
+	if c.Consumer == "" {
+		host, _ := os.Hostname()
+		c.Consumer = host + "-" + strconv.Itoa(os.Getpid())
+	}
+	if len(c.Queues) == 0 {
+		c.Queues = []QueueConfig{{Name: DefaultQueue}}
+	}
+	for i := range c.Queues {
+		if c.Queues[i].Concurrency <= 0 {
+			c.Queues[i].Concurrency = 10
+		}
+	}
+	if c.MaxRetries <= 0 {
+		c.MaxRetries = 5
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 10 * time.Minute
+	}
+	if c.VisibilityTimeout <= 0 {
+		c.VisibilityTimeout = 5 * time.Minute
+	}
+	if c.ClaimInterval <= 0 {
+		c.ClaimInterval = 30 * time.Second
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.BlockTimeout <= 0 {
+		c.BlockTimeout = 5 * time.Second
+	}
+}
+
+// streamKey 队列流
+func (c *JobConfig) streamKey(queue string) string {
+	return c.Prefix + queue
+}
+
+// delayedKey 延迟任务有序集合，分值为执行时间（Unix 毫秒）
+func (c *JobConfig) delayedKey(queue string) string {
+	return c.Prefix + queue + ":delayed"
+}
+
+// deadKey 死信流
+func (c *JobConfig) deadKey(queue string) string {
+	return c.Prefix + queue + ":dead"
+}
+
+// handlerTimeout 处理器的超时时间，比可见性超时少 10% 的安全余量，
+// 保证超时的处理器返回并确认消息之前，消息不会被其他消费者重新领取
+func (c *JobConfig) handlerTimeout() time.Duration {
+	return c.VisibilityTimeout - c.VisibilityTimeout/10
+}
+
+// backoff 返回第 attempt 次失败后的重试等待时间
+func (c *JobConfig) backoff(attempt int) time.Duration {
+	d := c.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= c.MaxBackoff {
+			return c.MaxBackoff
+		}
+	}
+	return d
+}
+
+// Job 后台任务
+type Job struct {
+	ID         string            `json:"id"`                   // 任务ID
+	Queue      string            `json:"queue"`                // 所属队列
+	Type       string            `json:"type"`                 // 任务类型，决定由哪个处理器处理
+	Payload    json.RawMessage   `json:"payload"`              // 任务参数（JSON）
+	Attempt    int               `json:"attempt"`              // 已失败次数
+	MaxRetries int               `json:"max_retries"`          // 最大重试次数
+	Headers    map[string]string `json:"headers,omitempty"`    // 链路追踪上下文
+	LastError  string            `json:"last_error,omitempty"` // 最后一次失败原因
+	EnqueuedAt time.Time         `json:"enqueued_at"`          // 入队时间
+}
+
+// Decode 将任务参数解码到 v
+func (j *Job) Decode(v interface{}) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// Option 入队选项
+type Option func(*Job, *time.Time)
+
+// Delay 延迟 d 后执行
+func Delay(d time.Duration) Option {
+	return func(_ *Job, runAt *time.Time) {
+		*runAt = time.Now().Add(d)
+	}
+}
+
+// At 在指定时间执行
+func At(t time.Time) Option {
+	return func(_ *Job, runAt *time.Time) {
+		*runAt = t
+	}
+}
+
+// MaxRetries 设置最大重试次数，0 表示不重试
+func MaxRetries(n int) Option {
+	return func(j *Job, _ *time.Time) {
+		j.MaxRetries = n
+	}
+}
+
+// WithID 指定任务ID，默认随机生成
+func WithID(id string) Option {
+	return func(j *Job, _ *time.Time) {
+		j.ID = id
+	}
+}
+
+// Client 任务生产者
+type Client struct {
+	rdb    *redis.Client
+	config *JobConfig
+}
+
+// NewClient 创建任务生产者
+func NewClient(rdb *redis.Client, config *JobConfig) *Client {
+	config.setDefaults()
+	return &Client{rdb: rdb, config: config}
+}
+
+// Enqueue 将任务加入队列，payload 编码为 JSON；使用 Delay 或 At 选项时作为延迟任务保存，到期后进入队列
+// 当前链路追踪上下文随任务保存，处理任务时延续同一调用链。失败时返回 CodeQueueError 业务错误
+func (c *Client) Enqueue(ctx context.Context, queue, jobType string, payload interface{}, opts ...Option) (*Job, error) {
+	if queue == "" {
+		queue = DefaultQueue
+	}
+	if jobType == "" {
+		return nil, common.NewError(common.CodeQueueError).WithCause(errors.New("job: type is empty"))
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, common.WrapError(fmt.Errorf("job: encode payload: %w", err), common.CodeQueueError)
+	}
+
+	job := &Job{
+		ID:         newJobID(),
+		Queue:      queue,
+		Type:       jobType,
+		Payload:    data,
+		MaxRetries: c.config.MaxRetries,
+		EnqueuedAt: time.Now(),
+	}
+	var runAt time.Time
+	for _, opt := range opts {
+		opt(job, &runAt)
+	}
+
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) > 0 {
+		job.Headers = carrier
+	}
+
+	if runAt.After(time.Now()) {
+		err = schedule(ctx, c.rdb, c.config, job, runAt)
+	} else {
+		err = push(ctx, c.rdb, c.config, job)
+	}
+	if err != nil {
+		return nil, common.WrapError(err, common.CodeQueueError)
+	}
+
+	if c.config.Metrics != nil {
+		c.config.Metrics.RecordJobEnqueued(queue, jobType)
+	}
+	return job, nil
+}
+
+// Redrive 将死信流中最多 count 个任务重新加入队列并重置失败次数，返回重新入队的任务数
+func (c *Client) Redrive(ctx context.Context, queue string, count int64) (int, error) {
+	messages, err := c.rdb.XRangeN(ctx, c.config.deadKey(queue), "-", "+", count).Result()
+	if err != nil {
+		return 0, common.WrapError(err, common.CodeQueueError)
+	}
+
+	n := 0
+	for _, msg := range messages {
+		job, err := decodeMessage(msg)
+		if err == nil {
+			job.Attempt = 0
+			job.LastError = ""
+			if err := push(ctx, c.rdb, c.config, job); err != nil {
+				return n, common.WrapError(err, common.CodeQueueError)
+			}
+			n++
+		}
+		if err := c.rdb.XDel(ctx, c.config.deadKey(queue), msg.ID).Err(); err != nil {
+			return n, common.WrapError(err, common.CodeQueueError)
+		}
+	}
+	return n, nil
+}
+
+// push 将任务写入队列流
+func push(ctx context.Context, rdb redis.Cmdable, config *JobConfig, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: config.streamKey(job.Queue),
+		MaxLen: config.MaxLen,
+		Approx: config.MaxLen > 0,
+		Values: map[string]interface{}{"job": data},
+	}).Err()
+}
+
+// schedule 将任务写入延迟任务集合
+func schedule(ctx context.Context, rdb redis.Cmdable, config *JobConfig, job *Job, runAt time.Time) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return rdb.ZAdd(ctx, config.delayedKey(job.Queue), redis.Z{
+		Score:  float64(runAt.UnixMilli()),
+		Member: data,
+	}).Err()
+}
+
+// decodeMessage 从流消息中解码任务
+func decodeMessage(msg redis.XMessage) (*Job, error) {
+	raw, ok := msg.Values["job"].(string)
+	if !ok {
+		return nil, fmt.Errorf("job: message %s has no job field", msg.ID)
+	}
+	job := &Job{}
+	if err := json.Unmarshal([]byte(raw), job); err != nil {
+		return nil, fmt.Errorf("job: decode message %s: %w", msg.ID, err)
+	}
+	return job, nil
+}
+
+// newJobID 生成随机任务ID
+func newJobID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,507 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/prometheus"
+)
+
+// promoteBatchSize 每次转移的到期延迟任务数
+const promoteBatchSize = 100
+
+// promoteScript 原子地将到期的延迟任务从有序集合转移到队列流，多个实例同时执行也不会重复入队
+var promoteScript = redis.NewScript(`
+local jobs = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[2]))
+for _, job in ipairs(jobs) do
+	redis.call('ZREM', KEYS[1], job)
+	if tonumber(ARGV[3]) > 0 then
+		redis.call('XADD', KEYS[2], 'MAXLEN', '~', ARGV[3], '*', 'job', job)
+	else
+		redis.call('XADD', KEYS[2], '*', 'job', job)
+	end
+end
+return #jobs
+`)
+
+// Handler 任务处理器
+type Handler interface {
+	Process(ctx context.Context, job *Job) error
+}
+
+// HandlerFunc 函数形式的任务处理器
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+// Process 处理任务
+func (f HandlerFunc) Process(ctx context.Context, job *Job) error {
+	return f(ctx, job)
+}
+
+// Register 注册类型化的任务处理器，任务参数解码为 T 后交给 fn 处理，解码失败的任务直接进入死信
+func Register[T any](w *Worker, jobType string, fn func(ctx context.Context, payload T) error) {
+	w.Handle(jobType, HandlerFunc(func(ctx context.Context, job *Job) error {
+		var payload T
+		if err := job.Decode(&payload); err != nil {
+			return Permanent(fmt.Errorf("job: decode payload: %w", err))
+		}
+		return fn(ctx, payload)
+	}))
+}
+
+// permanentError 不可重试的错误
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 标记错误不可重试，处理器返回该错误时任务直接进入死信
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Worker 任务消费者，实现 app.Component
+// 每个队列使用 Redis Streams 消费者组消费，并发数受 QueueConfig.Concurrency 限制；
+// 处理成功后确认消息，失败时按指数退避重新调度，超过最大重试次数后写入死信流；
+// 超过可见性超时仍未确认的任务（如进程崩溃）通过 XAUTOCLAIM 被重新领取，因此任务至少执行一次，处理器需保证幂等。
+// 需要 Redis 6.2 及以上版本
+type Worker struct {
+	rdb      *redis.Client
+	config   *JobConfig
+	logger   *zhlog.Helper
+	tracer   oteltrace.Tracer
+	mu       sync.RWMutex
+	handlers map[string]Handler
+
+	stop  context.CancelFunc // 停止领取新任务
+	abort context.CancelFunc // 中断处理中的任务
+	wg    sync.WaitGroup
+}
+
+// NewWorker 创建任务消费者
+func NewWorker(rdb *redis.Client, config *JobConfig, logger *zhlog.Helper) *Worker {
+	config.setDefaults()
+	return &Worker{
+		rdb:      rdb,
+		config:   config,
+		logger:   logger,
+		tracer:   otel.Tracer("go-template/pkg/job"),
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Handle 注册任务处理器，同一任务类型重复注册时覆盖
+func (w *Worker) Handle(jobType string, handler Handler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[jobType] = handler
+}
+
+// HandleFunc 注册函数形式的任务处理器
+func (w *Worker) HandleFunc(jobType string, fn func(ctx context.Context, job *Job) error) {
+	w.Handle(jobType, HandlerFunc(fn))
+}
+
+// Name 组件名称
+func (w *Worker) Name() string {
+	return "job-worker"
+}
+
+// Start 创建消费者组并在后台开始消费
+func (w *Worker) Start(ctx context.Context) error {
+	for _, queue := range w.config.Queues {
+		err := w.rdb.XGroupCreateMkStream(ctx, w.config.streamKey(queue.Name), w.config.Group, "0").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return fmt.Errorf("job: create consumer group for queue %s: %w", queue.Name, err)
+		}
+	}
+
+	fetchCtx, stop := context.WithCancel(context.Background())
+	jobCtx, abort := context.WithCancel(context.Background())
+	w.stop = stop
+	w.abort = abort
+
+	for _, queue := range w.config.Queues {
+		sem := make(chan struct{}, queue.Concurrency)
+		w.wg.Add(3)
+		go w.fetch(fetchCtx, jobCtx, queue.Name, sem)
+		go w.reclaim(fetchCtx, jobCtx, queue.Name, sem)
+		go w.promote(fetchCtx, queue.Name)
+	}
+
+	w.logger.Info("任务消费者已启动", "queues", len(w.config.Queues), "consumer", w.config.Consumer)
+	return nil
+}
+
+// Stop 优雅排空：停止领取新任务并等待处理中的任务完成，ctx 到期后中断剩余任务
+// 被中断的任务未确认，超过可见性超时后由其他消费者重新领取
+func (w *Worker) Stop(ctx context.Context) error {
+	if w.stop == nil {
+		return nil
+	}
+	w.stop()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.abort()
+		return nil
+	case <-ctx.Done():
+		w.abort()
+		return fmt.Errorf("job: drain interrupted: %w", ctx.Err())
+	}
+}
+
+// fetch 领取新任务：先占用并发名额，再阻塞读取一条消息
+func (w *Worker) fetch(ctx, jobCtx context.Context, queue string, sem chan struct{}) {
+	defer w.wg.Done()
+
+	stream := w.config.streamKey(queue)
+	for {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		streams, err := w.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    w.config.Group,
+			Consumer: w.config.Consumer,
+			Streams:  []string{stream, ">"},
+			Count:    1,
+			Block:    w.config.BlockTimeout,
+		}).Result()
+		claimedAt := time.Now()
+		if err != nil || len(streams) == 0 || len(streams[0].Messages) == 0 {
+			<-sem
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil && !errors.Is(err, redis.Nil) {
+				w.logger.Warn("读取任务失败", "queue", queue, "error", err)
+				w.sleep(ctx, time.Second)
+			}
+			continue
+		}
+
+		w.dispatch(jobCtx, queue, streams[0].Messages[0], 1, claimedAt, sem)
+	}
+}
+
+// reclaim 定期领取超过可见性超时仍未确认的任务
+func (w *Worker) reclaim(ctx, jobCtx context.Context, queue string, sem chan struct{}) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.config.ClaimInterval)
+	defer ticker.Stop()
+
+	stream := w.config.streamKey(queue)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		start := "0-0"
+		for ctx.Err() == nil {
+			free := cap(sem) - len(sem)
+			if free <= 0 {
+				break
+			}
+
+			claimedAt := time.Now()
+			messages, next, err := w.rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+				Stream:   stream,
+				Group:    w.config.Group,
+				Consumer: w.config.Consumer,
+				MinIdle:  w.config.VisibilityTimeout,
+				Start:    start,
+				Count:    int64(free),
+			}).Result()
+			if err != nil {
+				if ctx.Err() == nil {
+					w.logger.Warn("领取超时任务失败", "queue", queue, "error", err)
+				}
+				break
+			}
+
+			if len(messages) > 0 {
+				w.logger.Warn("重新领取超时未确认的任务", "queue", queue, "count", len(messages))
+				if w.config.Metrics != nil {
+					w.config.Metrics.RecordJobsReclaimed(queue, len(messages))
+				}
+			}
+			deliveries := w.deliveries(ctx, stream, messages)
+			for _, msg := range messages {
+				select {
+				case sem <- struct{}{}:
+					w.dispatch(jobCtx, queue, msg, deliveries[msg.ID], claimedAt, sem)
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if next == "0-0" || next == "" || len(messages) == 0 {
+				break
+			}
+			start = next
+		}
+	}
+}
+
+// deliveries 查询消息的投递次数，查询失败的消息按两次投递计算（至少被领取过一次）
+func (w *Worker) deliveries(ctx context.Context, stream string, messages []redis.XMessage) map[string]int64 {
+	counts := make(map[string]int64, len(messages))
+	if len(messages) == 0 {
+		return counts
+	}
+
+	cmds := make([]*redis.XPendingExtCmd, len(messages))
+	_, _ = w.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, msg := range messages {
+			cmds[i] = pipe.XPendingExt(ctx, &redis.XPendingExtArgs{
+				Stream: stream,
+				Group:  w.config.Group,
+				Start:  msg.ID,
+				End:    msg.ID,
+				Count:  1,
+			})
+		}
+		return nil
+	})
+	for i, msg := range messages {
+		counts[msg.ID] = 2
+		pending, err := cmds[i].Result()
+		if err != nil {
+			if ctx.Err() == nil {
+				w.logger.Warn("查询任务投递次数失败", "stream", stream, "message_id", msg.ID, "error", err)
+			}
+			continue
+		}
+		if len(pending) > 0 && pending[0].RetryCount > 0 {
+			counts[msg.ID] = pending[0].RetryCount
+		}
+	}
+	return counts
+}
+
+// promote 定期将到期的延迟任务和重试任务转移到队列流
+func (w *Worker) promote(ctx context.Context, queue string) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	keys := []string{w.config.delayedKey(queue), w.config.streamKey(queue)}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for ctx.Err() == nil {
+			n, err := promoteScript.Run(ctx, w.rdb, keys, time.Now().UnixMilli(), promoteBatchSize, w.config.MaxLen).Int()
+			if err != nil {
+				if ctx.Err() == nil {
+					w.logger.Warn("转移延迟任务失败", "queue", queue, "error", err)
+				}
+				break
+			}
+			if n < promoteBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// dispatch 在独立的 goroutine 中处理任务，完成后释放并发名额
+func (w *Worker) dispatch(ctx context.Context, queue string, msg redis.XMessage, deliveries int64, claimedAt time.Time, sem chan struct{}) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer func() { <-sem }()
+		w.process(ctx, queue, msg, deliveries, claimedAt)
+	}()
+}
+
+// process 处理单条任务消息并根据结果确认、重试或写入死信
+// deliveries 为消息的投递次数，大于 1 说明之前的处理进程崩溃或超时未确认，每次计为一次失败；
+// claimedAt 为领取消息的时间，消息的空闲时间从此开始计算
+func (w *Worker) process(ctx context.Context, queue string, msg redis.XMessage, deliveries int64, claimedAt time.Time) {
+	job, err := decodeMessage(msg)
+	if err != nil {
+		w.logger.Error("无法解析的任务消息，写入死信流", "queue", queue, "message_id", msg.ID, "error", err)
+		w.deadLetter(queue, msg.ID, msg.Values, err)
+		return
+	}
+
+	if deliveries > 1 {
+		job.Attempt += int(deliveries - 1)
+		if job.Attempt > job.MaxRetries {
+			err := fmt.Errorf("job: not acknowledged after %d deliveries", deliveries)
+			job.LastError = err.Error()
+			w.logger.Error("任务多次超时未确认，写入死信流", "job_id", job.ID, "queue", queue, "type", job.Type, "attempt", job.Attempt, "error", err)
+			data, _ := json.Marshal(job)
+			w.deadLetter(queue, msg.ID, map[string]interface{}{"job": data}, err)
+			if w.config.Metrics != nil {
+				w.config.Metrics.RecordJobStarted(queue)
+				w.config.Metrics.RecordJobFinished(queue, job.Type, prometheus.JobDead, 0)
+			}
+			return
+		}
+	}
+
+	start := time.Now()
+	if w.config.Metrics != nil {
+		w.config.Metrics.RecordJobStarted(queue)
+	}
+
+	// 延续入队时的调用链
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(job.Headers))
+	ctx, span := w.tracer.Start(ctx, "job "+job.Type,
+		oteltrace.WithSpanKind(oteltrace.SpanKindConsumer),
+		oteltrace.WithAttributes(
+			attribute.String("job.id", job.ID),
+			attribute.String("job.queue", queue),
+			attribute.String("job.type", job.Type),
+			attribute.Int("job.attempt", job.Attempt),
+		),
+	)
+	defer span.End()
+
+	// 等待并发名额的时间也计入空闲时间，截止时间从领取时刻起算，并在可见性超时前预留确认的余量
+	runCtx, cancel := context.WithDeadline(ctx, claimedAt.Add(w.config.handlerTimeout()))
+	err = w.run(runCtx, job)
+	cancel()
+
+	result := w.finish(queue, msg.ID, job, err)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, result)
+	}
+	if w.config.Metrics != nil {
+		w.config.Metrics.RecordJobFinished(queue, job.Type, result, time.Since(start))
+	}
+}
+
+// run 调用任务处理器，处理器 panic 时转换为错误
+func (w *Worker) run(ctx context.Context, job *Job) (err error) {
+	w.mu.RLock()
+	handler, ok := w.handlers[job.Type]
+	w.mu.RUnlock()
+	if !ok {
+		return Permanent(fmt.Errorf("job: no handler registered for type %q", job.Type))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job: panic: %v", r)
+			w.logger.Error("任务处理器 panic", "job_id", job.ID, "type", job.Type, "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+	return handler.Process(ctx, job)
+}
+
+// finish 根据处理结果确认消息，返回结果类型
+// 确认失败时消息保留在待确认列表中，超过可见性超时后会被重新领取
+func (w *Worker) finish(queue, messageID string, job *Job, err error) string {
+	ctx := context.Background()
+	stream := w.config.streamKey(queue)
+
+	if err == nil {
+		_, perr := w.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.XAck(ctx, stream, w.config.Group, messageID)
+			pipe.XDel(ctx, stream, messageID)
+			return nil
+		})
+		if perr != nil {
+			w.logger.Error("确认任务失败", "job_id", job.ID, "queue", queue, "error", perr)
+		}
+		return prometheus.JobSucceeded
+	}
+
+	job.Attempt++
+	job.LastError = err.Error()
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) || job.Attempt > job.MaxRetries {
+		w.logger.Error("任务处理失败，写入死信流", "job_id", job.ID, "queue", queue, "type", job.Type, "attempt", job.Attempt, "error", err)
+		data, _ := json.Marshal(job)
+		w.deadLetter(queue, messageID, map[string]interface{}{"job": data}, err)
+		return prometheus.JobDead
+	}
+
+	backoff := w.config.backoff(job.Attempt)
+	w.logger.Warn("任务处理失败，稍后重试", "job_id", job.ID, "queue", queue, "type", job.Type, "attempt", job.Attempt, "backoff", backoff, "error", err)
+	_, perr := w.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if err := schedule(ctx, pipe, w.config, job, time.Now().Add(backoff)); err != nil {
+			return err
+		}
+		pipe.XAck(ctx, stream, w.config.Group, messageID)
+		pipe.XDel(ctx, stream, messageID)
+		return nil
+	})
+	if perr != nil {
+		w.logger.Error("重新调度任务失败", "job_id", job.ID, "queue", queue, "error", perr)
+	}
+	return prometheus.JobRetried
+}
+
+// deadLetter 将消息写入死信流并从队列中移除
+func (w *Worker) deadLetter(queue, messageID string, values map[string]interface{}, cause error) {
+	ctx := context.Background()
+	stream := w.config.streamKey(queue)
+
+	fields := make(map[string]interface{}, len(values)+2)
+	for k, v := range values {
+		fields[k] = v
+	}
+	fields["error"] = cause.Error()
+	fields["failed_at"] = time.Now().UnixMilli()
+
+	_, err := w.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: w.config.deadKey(queue), Values: fields})
+		pipe.XAck(ctx, stream, w.config.Group, messageID)
+		pipe.XDel(ctx, stream, messageID)
+		return nil
+	})
+	if err != nil {
+		w.logger.Error("写入死信流失败", "queue", queue, "message_id", messageID, "error", err)
+	}
+}
+
+// sleep 等待 d 或 ctx 取消
+func (w *Worker) sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
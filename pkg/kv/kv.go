@@ -0,0 +1,38 @@
+package kv
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound 键不存在
+var ErrNotFound = errors.New("kv: key not found")
+
+// Entry 键值
+type Entry struct {
+	Key      string // 键
+	Value    []byte // 值，监听时键被删除则为 nil
+	Revision int64  // 最后修改版本（etcd 的 ModRevision、Consul 的 ModifyIndex），用于比较并交换
+}
+
+// Store 键值存储，由 etcd 和 Consul 分别实现，服务可通过配置切换后端
+type Store interface {
+	// Get 读取键值，键不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (*Entry, error)
+	// Put 写入键值
+	Put(ctx context.Context, key string, value []byte) error
+	// CompareAndSwap 仅当键的当前版本等于 revision 时写入，revision 为 0 表示仅当键不存在时写入；返回是否写入成功
+	CompareAndSwap(ctx context.Context, key string, value []byte, revision int64) (bool, error)
+	// Delete 删除键，键不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// Watch 监听键的变化，ctx 取消后监听结束
+	Watch(ctx context.Context, key string) (Watcher, error)
+}
+
+// Watcher 键监听器
+type Watcher interface {
+	// Next 首次调用立即返回当前值（键不存在时 Value 为 nil），之后阻塞直到键发生变化
+	Next() (*Entry, error)
+	// Stop 停止监听
+	Stop() error
+}
@@ -0,0 +1,298 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 默认允许的方法和请求头
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead}
+	defaultCORSHeaders = []string{"Accept", "Authorization", "Content-Type", "X-Requested-With", "X-Request-ID"}
+)
+
+// CORSPolicy 跨域策略
+type CORSPolicy struct {
+	// 允许的来源，支持以下写法：
+	//   "https://example.com"        精确匹配
+	//   "https://*.example.com"      匹配任意子域名（不包含 example.com 本身）
+	//   "regex:https://.+\.a\.com"   正则匹配，自动添加首尾锚点，须匹配整个来源
+	//   "*"                          允许任意来源，不能与 AllowCredentials 同时使用
+	// 为空时不输出任何跨域响应头
+	AllowedOrigins   []string `toml:"allowed_origins"`
+	AllowedMethods   []string `toml:"allowed_methods"`   // 为空时使用默认方法列表
+	AllowedHeaders   []string `toml:"allowed_headers"`   // 为空时使用默认请求头列表，"*" 表示允许预检请求声明的任意请求头
+	ExposedHeaders   []string `toml:"exposed_headers"`   // 允许浏览器读取的响应头
+	AllowCredentials bool     `toml:"allow_credentials"` // 是否允许携带 Cookie 等凭证
+	MaxAge           int      `toml:"max_age"`           // 预检结果缓存时间（秒），0 表示不缓存
+}
+
+// CORSGroupPolicy 针对路由分组的跨域策略，完全替代默认策略
+type CORSGroupPolicy struct {
+	Prefix     string `toml:"prefix"` // 路径前缀，如 "/api/admin"
+	CORSPolicy        // 该分组使用的策略
+}
+
+// CORSConfig 跨域配置，对应配置文件中的 [cors] 段
+type CORSConfig struct {
+	CORSPolicy                   // 默认策略
+	Groups     []CORSGroupPolicy `toml:"groups"` // 按路由分组覆盖，对应 [[cors.groups]]，按最长前缀匹配
+}
+
+// Validate 检查跨域配置，来源写法无效或 "*" 与凭证同时使用时返回错误
+func (config *CORSConfig) Validate() error {
+	_, err := CORS(config)
+	return err
+}
+
+// CORS 跨域中间件
+// 需注册在路由器全局（router.Use），以便处理未注册 OPTIONS 路由的预检请求；
+// 配置无效时返回错误，见 CORSConfig.Validate
+func CORS(config *CORSConfig) (gin.HandlerFunc, error) {
+	defaultPolicy, err := compileCORSPolicy(&config.CORSPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]*corsGroup, 0, len(config.Groups))
+	for i := range config.Groups {
+		group := &config.Groups[i]
+		policy, err := compileCORSPolicy(&group.CORSPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("cors: group %s: %w", group.Prefix, err)
+		}
+		groups = append(groups, &corsGroup{
+			prefix: strings.TrimSuffix(group.Prefix, "/"),
+			policy: policy,
+		})
+	}
+	// 前缀越长越优先
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i].prefix) > len(groups[j].prefix)
+	})
+
+	return func(c *gin.Context) {
+		policy := defaultPolicy
+		for _, group := range groups {
+			if group.match(c.Request.URL.Path) {
+				policy = group.policy
+				break
+			}
+		}
+		policy.handle(c)
+	}, nil
+}
+
+// corsGroup 已编译的分组策略
+type corsGroup struct {
+	prefix string
+	policy *corsPolicy
+}
+
+// match 判断路径是否属于该分组，按路径段匹配，"/api/admin" 不匹配 "/api/administrator"
+func (g *corsGroup) match(path string) bool {
+	if g.prefix == "" {
+		return true
+	}
+	return path == g.prefix || strings.HasPrefix(path, g.prefix+"/")
+}
+
+// corsPolicy 已编译的跨域策略
+type corsPolicy struct {
+	enabled          bool
+	allowAll         bool
+	exact            map[string]bool
+	wildcards        [][2]string // 通配符拆分后的前缀和后缀
+	patterns         []*regexp.Regexp
+	methods          map[string]bool
+	methodsValue     string
+	headers          map[string]bool
+	headersValue     string
+	allowAllHeaders  bool
+	exposedValue     string
+	allowCredentials bool
+	maxAge           string
+}
+
+// compileCORSPolicy 编译跨域策略
+func compileCORSPolicy(policy *CORSPolicy) (*corsPolicy, error) {
+	p := &corsPolicy{
+		enabled:          len(policy.AllowedOrigins) > 0,
+		exact:            make(map[string]bool),
+		methods:          make(map[string]bool),
+		headers:          make(map[string]bool),
+		allowCredentials: policy.AllowCredentials,
+	}
+
+	for _, origin := range policy.AllowedOrigins {
+		switch {
+		case origin == "*":
+			// 携带凭证时只能回显具体来源，允许任意来源等于允许任意站点发起带凭证的请求
+			if policy.AllowCredentials {
+				return nil, errors.New(`cors: allowed origin "*" cannot be used with allow_credentials`)
+			}
+			p.allowAll = true
+		case strings.HasPrefix(origin, "regex:"):
+			// 添加首尾锚点，避免 https://.+\.a\.com 匹配 https://a.com.evil.net
+			re, err := regexp.Compile(`^(?:` + strings.TrimPrefix(origin, "regex:") + `)$`)
+			if err != nil {
+				return nil, fmt.Errorf("cors: invalid origin pattern %q: %w", origin, err)
+			}
+			p.patterns = append(p.patterns, re)
+		case strings.Contains(origin, "*"):
+			prefix, suffix, _ := strings.Cut(strings.ToLower(origin), "*")
+			if strings.Contains(suffix, "*") {
+				return nil, fmt.Errorf("cors: origin %q contains more than one wildcard", origin)
+			}
+			p.wildcards = append(p.wildcards, [2]string{prefix, suffix})
+		default:
+			p.exact[strings.ToLower(origin)] = true
+		}
+	}
+
+	methods := policy.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	upper := make([]string, 0, len(methods))
+	for _, method := range methods {
+		method = strings.ToUpper(method)
+		p.methods[method] = true
+		upper = append(upper, method)
+	}
+	p.methodsValue = strings.Join(upper, ", ")
+
+	headers := policy.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	for _, header := range headers {
+		if header == "*" {
+			p.allowAllHeaders = true
+			continue
+		}
+		p.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	p.headersValue = strings.Join(headers, ", ")
+
+	p.exposedValue = strings.Join(policy.ExposedHeaders, ", ")
+	if policy.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(policy.MaxAge)
+	}
+	return p, nil
+}
+
+// allowOrigin 判断来源是否被允许
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.allowAll {
+		return true
+	}
+	lower := strings.ToLower(origin)
+	if p.exact[lower] {
+		return true
+	}
+	for _, w := range p.wildcards {
+		if len(lower) > len(w[0])+len(w[1]) && strings.HasPrefix(lower, w[0]) && strings.HasSuffix(lower, w[1]) {
+			return true
+		}
+	}
+	for _, re := range p.patterns {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowHeaders 判断预检请求声明的请求头是否全部被允许
+func (p *corsPolicy) allowHeaders(requested string) bool {
+	if p.allowAllHeaders || requested == "" {
+		return true
+	}
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !p.headers[http.CanonicalHeaderKey(header)] {
+			return false
+		}
+	}
+	return true
+}
+
+// handle 处理跨域请求
+func (p *corsPolicy) handle(c *gin.Context) {
+	if !p.enabled {
+		c.Next()
+		return
+	}
+
+	origin := c.GetHeader("Origin")
+	preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+	// 除了对所有来源返回固定 "*" 的情况，响应内容取决于 Origin，需要告知缓存按 Origin 区分
+	h := c.Writer.Header()
+	if !p.allowAll {
+		h.Add("Vary", "Origin")
+	}
+	if preflight {
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+	}
+
+	if origin == "" {
+		c.Next()
+		return
+	}
+
+	if !p.allowOrigin(origin) {
+		if preflight {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Next()
+		return
+	}
+
+	// 允许任意来源时不会携带凭证（见 compileCORSPolicy），可以返回固定的 "*"
+	if p.allowAll {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+	}
+	if p.allowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if !preflight {
+		if p.exposedValue != "" {
+			h.Set("Access-Control-Expose-Headers", p.exposedValue)
+		}
+		c.Next()
+		return
+	}
+
+	requestedHeaders := c.GetHeader("Access-Control-Request-Headers")
+	if !p.methods[strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))] || !p.allowHeaders(requestedHeaders) {
+		h.Del("Access-Control-Allow-Origin")
+		h.Del("Access-Control-Allow-Credentials")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	h.Set("Access-Control-Allow-Methods", p.methodsValue)
+	if p.allowAllHeaders && requestedHeaders != "" {
+		h.Set("Access-Control-Allow-Headers", requestedHeaders)
+	} else if !p.allowAllHeaders {
+		h.Set("Access-Control-Allow-Headers", p.headersValue)
+	}
+	if p.maxAge != "" {
+		h.Set("Access-Control-Max-Age", p.maxAge)
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCORSRejectsWildcardWithCredentials(t *testing.T) {
+	config := &CORSConfig{CORSPolicy: CORSPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true}}
+	if err := config.Validate(); err == nil {
+		t.Fatal("want error for \"*\" with allow_credentials")
+	}
+
+	config = &CORSConfig{Groups: []CORSGroupPolicy{{
+		Prefix:     "/api/admin",
+		CORSPolicy: CORSPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+	}}}
+	if err := config.Validate(); err == nil {
+		t.Fatal("want error for group with \"*\" and allow_credentials")
+	}
+
+	config = &CORSConfig{CORSPolicy: CORSPolicy{AllowedOrigins: []string{"regex:("}}}
+	if err := config.Validate(); err == nil {
+		t.Fatal("want error for invalid pattern")
+	}
+}
+
+func TestCORSRegexIsAnchored(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cors, err := CORS(&CORSConfig{CORSPolicy: CORSPolicy{AllowedOrigins: []string{`regex:https://.+\.a\.com`}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.Use(cors)
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://x.a.com", true},
+		{"https://a.com.evil.net", false},
+		{"https://x.a.com.evil.net", false},
+		{"evil://https://x.a.com", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", tt.origin)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		got := w.Header().Get("Access-Control-Allow-Origin") == tt.origin
+		if got != tt.allowed {
+			t.Errorf("origin %s: allowed = %v, want %v", tt.origin, got, tt.allowed)
+		}
+	}
+}
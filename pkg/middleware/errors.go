@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"go-template/utils/common"
+)
+
+// ErrorHandlerConfig 错误处理配置
+type ErrorHandlerConfig struct {
+	Logger *zhlog.Helper // 日志记录器
+}
+
+// ErrorHandler 错误处理中间件
+// 处理器通过 c.Error(err) 返回错误后，若尚未写响应，则将最后一个错误转换为 common.Error 并输出
+// BusinessResponse / BusinessResponseWithMessage；底层错误写入日志并记录到当前span
+func ErrorHandler(config *ErrorHandlerConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		e := common.FromError(err)
+		requestID := GetRequestID(c)
+
+		fields := []interface{}{
+			"请求处理失败",
+			"code", int(e.Code),
+			"error", err,
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"client_ip", c.ClientIP(),
+			"request_id", requestID,
+		}
+		serverError := e.HTTPStatus() >= 500
+		if serverError {
+			config.Logger.Error(fields...)
+		} else {
+			config.Logger.Warn(fields...)
+		}
+
+		span := oteltrace.SpanFromContext(c.Request.Context())
+		span.RecordError(err, oteltrace.WithAttributes(attribute.Int("business.code", int(e.Code))))
+		if serverError {
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		// 处理器已自行写出响应时不再覆盖
+		if c.Writer.Written() {
+			return
+		}
+
+		data := e.Details
+		if data == nil && serverError {
+			data = gin.H{"request_id": requestID}
+		}
+		if e.Message != "" {
+			common.BusinessResponseWithMessage(c, e.Code, common.FormatMessage(e.Message, e.Params), data)
+		} else {
+			common.BusinessResponseWithParams(c, e.Code, e.Params, data)
+		}
+	}
+}
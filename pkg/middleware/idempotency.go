@@ -0,0 +1,265 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/utils/common"
+)
+
+const (
+	// IdempotencyKeyHeader 幂等键请求头
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader 重放响应时设置的响应头
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotencyKeyLength 幂等键最大长度
+const maxIdempotencyKeyLength = 255
+
+// 幂等记录状态
+const (
+	idempotencyProcessing = "processing"
+	idempotencyCompleted  = "completed"
+)
+
+// IdempotencyConfig 幂等中间件配置
+type IdempotencyConfig struct {
+	Redis       *redis.Client               // Redis 客户端
+	Logger      *zhlog.Helper               // 日志记录器
+	Prefix      string                      // 键前缀，默认 idempotency:
+	TTL         time.Duration               // 已完成响应的保存时间，默认 24h
+	LockTTL     time.Duration               // 处理中状态的最长保留时间，应大于请求超时时间，默认 1m
+	Methods     []string                    // 启用的请求方法，默认 POST、PUT、PATCH、DELETE
+	Scope       func(c *gin.Context) string // 返回幂等键的命名空间，避免不同用户的键冲突；默认为 gin.Context 中的 user_id，未认证时为客户端IP
+	MaxBodySize int64                       // 计算指纹时读取的请求体最大字节数，超出时返回 CodeMessageTooLarge，默认 1MB
+}
+
+// idempotencyRecord 保存在 Redis 中的幂等记录
+type idempotencyRecord struct {
+	State       string          `json:"state"`
+	Token       string          `json:"token,omitempty"` // 处理中状态的持有者标识，完成时校验
+	Fingerprint string          `json:"fingerprint"`
+	Status      int             `json:"status,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"` // common.Response 响应体，没有响应体时为空
+}
+
+// completeIdempotencyScript 仅当处理中状态仍由本请求持有时写入完成记录
+var completeIdempotencyScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if current and cjson.decode(current)["token"] == ARGV[1] then
+	return redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+end
+return false
+`)
+
+// releaseIdempotencyScript 仅当处理中状态仍由本请求持有时删除
+var releaseIdempotencyScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if current and cjson.decode(current)["token"] == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Idempotency 幂等中间件
+// 读取 Idempotency-Key 请求头，以请求方法、路径和请求体计算指纹，首次请求的响应（状态码和 common.Response 响应体）
+// 保存在 Redis 中，相同键的重复请求直接重放该响应并设置 Idempotent-Replayed: true；
+// 首次请求仍在处理中或相同键携带不同的请求内容时返回 CodeConflict。
+// 没有响应体的响应只保存状态码；5xx 响应和非 JSON 响应不保存，客户端可以使用相同的键重试；Redis 不可用时跳过幂等检查继续处理请求
+func Idempotency(config *IdempotencyConfig) gin.HandlerFunc {
+	if config.Prefix == "" {
+		config.Prefix = "idempotency:"
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.LockTTL <= 0 {
+		config.LockTTL = time.Minute
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	if config.Scope == nil {
+		config.Scope = defaultIdempotencyScope
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 1 << 20
+	}
+	methods := make(map[string]bool, len(config.Methods))
+	for _, m := range config.Methods {
+		methods[m] = true
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !methods[c.Request.Method] {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			common.BusinessResponse(c, common.CodeBadRequest, nil)
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, config.MaxBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				common.BusinessResponse(c, common.CodeMessageTooLarge, nil)
+			} else {
+				common.BusinessResponse(c, common.CodeBadRequest, nil)
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key = config.Scope(c) + ":" + key
+		redisKey := config.Prefix + key
+		fingerprint := requestFingerprint(c.Request, body)
+		token := newRequestID()
+
+		// 客户端断开后仍需保存响应或释放键
+		ctx := context.WithoutCancel(c.Request.Context())
+		lock, _ := json.Marshal(idempotencyRecord{State: idempotencyProcessing, Token: token, Fingerprint: fingerprint})
+		acquired, err := config.Redis.SetNX(ctx, redisKey, lock, config.LockTTL).Result()
+		if err != nil {
+			config.Logger.Warn("幂等检查失败，跳过", "key", key, "error", err)
+			c.Next()
+			return
+		}
+		if !acquired {
+			replayIdempotent(c, config, redisKey, fingerprint)
+			return
+		}
+
+		w := &captureWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		completed := false
+		defer func() {
+			c.Writer = w.ResponseWriter
+			if completed {
+				return
+			}
+			// 处理器 panic 或响应不可保存时释放键，允许客户端重试
+			if err := releaseIdempotencyScript.Run(ctx, config.Redis, []string{redisKey}, token).Err(); err != nil {
+				config.Logger.Warn("释放幂等键失败", "key", key, "error", err)
+			}
+		}()
+
+		c.Next()
+
+		// 没有响应体（如 204）时只保存状态码
+		status := w.Status()
+		if status >= http.StatusInternalServerError || (w.body.Len() > 0 && !json.Valid(w.body.Bytes())) {
+			return
+		}
+		record, _ := json.Marshal(idempotencyRecord{
+			State:       idempotencyCompleted,
+			Fingerprint: fingerprint,
+			Status:      status,
+			Body:        w.body.Bytes(),
+		})
+		err = completeIdempotencyScript.Run(ctx, config.Redis, []string{redisKey}, token, record, config.TTL.Milliseconds()).Err()
+		if errors.Is(err, redis.Nil) {
+			// 处理时间超过 LockTTL，键已过期或被其他请求占用，不能覆盖也无需释放
+			config.Logger.Warn("幂等键已不再由本请求持有，响应未保存", "key", key, "lock_ttl", config.LockTTL)
+			completed = true
+			return
+		}
+		if err != nil {
+			config.Logger.Warn("保存幂等响应失败", "key", key, "error", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// replayIdempotent 处理已存在幂等记录的请求
+func replayIdempotent(c *gin.Context, config *IdempotencyConfig, redisKey, fingerprint string) {
+	data, err := config.Redis.Get(c.Request.Context(), redisKey).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			// 首次请求刚刚失败并释放了键，按并发冲突处理，由客户端重试
+			common.BusinessResponse(c, common.CodeConflict, nil)
+			c.Abort()
+			return
+		}
+		config.Logger.Warn("读取幂等记录失败，跳过", "key", redisKey, "error", err)
+		c.Next()
+		return
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		config.Logger.Warn("幂等记录格式错误", "key", redisKey, "error", err)
+		common.BusinessResponse(c, common.CodeConflict, nil)
+		c.Abort()
+		return
+	}
+	if record.Fingerprint != fingerprint || record.State != idempotencyCompleted {
+		common.BusinessResponse(c, common.CodeConflict, nil)
+		c.Abort()
+		return
+	}
+
+	c.Header(IdempotentReplayedHeader, "true")
+	if len(record.Body) == 0 {
+		c.AbortWithStatus(record.Status)
+		return
+	}
+	c.Data(record.Status, "application/json; charset=utf-8", record.Body)
+	c.Abort()
+}
+
+// requestFingerprint 以请求方法、路径、查询参数和请求体计算指纹
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(r.URL.RequestURI()))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// captureWriter 在写出响应的同时保存响应体
+type captureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// defaultIdempotencyScope 默认命名空间：认证中间件写入的 user_id，未认证时为客户端IP
+func defaultIdempotencyScope(c *gin.Context) string {
+	if v, ok := c.Get("user_id"); ok && v != nil {
+		if userID := fmt.Sprint(v); userID != "" {
+			return "user:" + userID
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+	"syscall"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"go-template/pkg/prometheus"
+	"go-template/utils/common"
+)
+
+// RecoveryConfig panic恢复配置
+type RecoveryConfig struct {
+	Logger  *zhlog.Helper       // 日志记录器，通过 pkg/helper 创建
+	Metrics *prometheus.Metrics // 可选，用于统计panic次数
+}
+
+// Recovery panic恢复中间件，替代 gin.Recovery
+// 记录panic及堆栈、请求上下文，并记录到当前span，返回 BusinessResponse(c, CodeInternalError, {request_id})；
+// 客户端断开连接（broken pipe / connection reset）导致的panic只记录警告，不再写响应；
+// http.ErrAbortHandler 重新抛出，由 net/http 中止响应
+func Recovery(config *RecoveryConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+
+			requestID := GetRequestID(c)
+			span := oteltrace.SpanFromContext(c.Request.Context())
+			err, ok := rec.(error)
+			if !ok {
+				err = fmt.Errorf("%v", rec)
+			}
+
+			// 处理器（如 httputil.ReverseProxy）主动中止响应，交给 net/http 断开连接，避免截断的响应被当作正常完成
+			if errors.Is(err, http.ErrAbortHandler) {
+				panic(http.ErrAbortHandler)
+			}
+
+			if isBrokenPipe(err) {
+				config.Logger.Warn("客户端连接已断开",
+					"error", err,
+					"method", c.Request.Method,
+					"path", c.Request.URL.Path,
+					"client_ip", c.ClientIP(),
+					"request_id", requestID,
+				)
+				span.RecordError(err, oteltrace.WithAttributes(attribute.Bool("http.broken_pipe", true)))
+				_ = c.Error(err)
+				c.Abort()
+				return
+			}
+
+			stack := string(debug.Stack())
+			config.Logger.Error("请求处理发生panic",
+				"panic", rec,
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"route", c.FullPath(),
+				"query", c.Request.URL.RawQuery,
+				"client_ip", c.ClientIP(),
+				"user_agent", c.Request.UserAgent(),
+				"request_id", requestID,
+				"stack", stack,
+			)
+
+			span.RecordError(err, oteltrace.WithAttributes(
+				attribute.String("exception.type", "panic"),
+				attribute.String("exception.stacktrace", stack),
+			))
+			span.SetStatus(codes.Error, "panic recovered")
+
+			if config.Metrics != nil {
+				config.Metrics.RecordPanic(c.Request.Method, c.FullPath())
+			}
+
+			// 处理器已开始写响应时无法再输出统一格式，只能中止
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			common.BusinessResponse(c, common.CodeInternalError, gin.H{"request_id": requestID})
+			c.Abort()
+		}()
+
+		c.Next()
+	}
+}
+
+// isBrokenPipe 判断是否为客户端断开连接导致的错误
+func isBrokenPipe(err error) bool {
+	if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		var syscallErr *os.SyscallError
+		if errors.As(opErr.Err, &syscallErr) {
+			msg := strings.ToLower(syscallErr.Error())
+			return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RequestIDHeader 请求ID请求头/响应头
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey 请求ID在 gin.Context 中的键
+	RequestIDKey = "request_id"
+)
+
+// maxRequestIDLength 客户端传入的请求ID最大长度，超出时重新生成
+const maxRequestIDLength = 128
+
+// RequestID 请求ID中间件
+// 优先使用客户端传入的 X-Request-ID，否则生成新的ID，并写入响应头和 gin.Context
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// GetRequestID 获取当前请求的请求ID，未使用 RequestID 中间件时返回请求头中的值
+func GetRequestID(c *gin.Context) string {
+	if id := c.GetString(RequestIDKey); id != "" {
+		return id
+	}
+	return c.GetHeader(RequestIDHeader)
+}
+
+// newRequestID 生成32位十六进制随机请求ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
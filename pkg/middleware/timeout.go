@@ -0,0 +1,297 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go-template/pkg/prometheus"
+	"go-template/utils/common"
+)
+
+// TimeoutConfig 请求超时配置
+type TimeoutConfig struct {
+	Timeout time.Duration            // 默认超时时间，为0时不限制
+	Routes  map[string]time.Duration // 按路由覆盖，key 为 "GET /api/v1/users/:id" 或 "/api/v1/export"，值为0表示不限制
+	Metrics *prometheus.Metrics      // 可选，用于统计超时次数
+}
+
+// timeoutFor 返回指定路由的超时时间，方法+路由的配置优先于仅路由的配置
+func (tc *TimeoutConfig) timeoutFor(method, route string) time.Duration {
+	if d, ok := tc.Routes[method+" "+route]; ok {
+		return d
+	}
+	if d, ok := tc.Routes[route]; ok {
+		return d
+	}
+	return tc.Timeout
+}
+
+// Timeout 请求超时中间件
+// 为每个请求设置带截止时间的 context，处理器应将 c.Request.Context() 传递给 GORM (WithContext)、Redis 和外部调用。
+// 截止时间到达时，如果处理器尚未开始写响应，则返回 BusinessResponse(c, CodeTimeout, nil)，此后处理器的写入会被丢弃；
+// 如果处理器已经开始写响应，则由处理器完成响应，不会出现两次写入。
+func Timeout(config *TimeoutConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := config.timeoutFor(c.Request.Method, c.FullPath())
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		// 超时响应使用上下文副本渲染，避免与处理器并发访问 c
+		cp := c.Copy()
+
+		tw := newTimeoutWriter(c.Writer)
+		c.Writer = tw
+
+		finished := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			select {
+			case <-finished:
+			case <-ctx.Done():
+				if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return
+				}
+				if tw.timeout(func(w gin.ResponseWriter) {
+					cp.Writer = w
+					common.BusinessResponse(cp, common.CodeTimeout, nil)
+				}) && config.Metrics != nil {
+					config.Metrics.RecordTimeout(cp.Request.Method, cp.FullPath())
+				}
+			}
+		}()
+
+		defer func() {
+			close(finished)
+			<-stopped
+
+			// 只设置了状态码或响应头而未写响应体（如 c.Status(204)、POST 后的重定向）时，由此写出到底层写入器
+			tw.mu.Lock()
+			if !tw.committed && !tw.timedOut {
+				tw.commit()
+			}
+			tw.mu.Unlock()
+			c.Writer = tw.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// timeoutWriter 由处理器使用的响应写入器
+// 处理器的响应头在真正写出前保存在独立的 header 中，写出时才复制到底层写入器，
+// 与超时响应通过互斥锁竞争“首次写出”的权利
+type timeoutWriter struct {
+	gin.ResponseWriter
+
+	mu        sync.Mutex
+	header    http.Header
+	status    int
+	committed bool // 处理器已开始写出响应
+	timedOut  bool // 已写出超时响应
+}
+
+func newTimeoutWriter(w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{
+		ResponseWriter: w,
+		header:         make(http.Header),
+		status:         http.StatusOK,
+	}
+}
+
+// timeout 尝试写出超时响应，处理器已开始写出时返回 false
+func (w *timeoutWriter) timeout(render func(w gin.ResponseWriter)) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.committed {
+		return false
+	}
+	w.timedOut = true
+
+	// 先渲染到缓冲区，再带 Content-Length 一次性写出，使客户端无需等待处理器返回即可读完响应
+	buf := &bufferWriter{ResponseWriter: w.ResponseWriter, header: make(http.Header), status: http.StatusOK}
+	render(buf)
+
+	dst := w.ResponseWriter.Header()
+	for k, v := range buf.header {
+		dst[k] = v
+	}
+	dst.Set("Content-Length", strconv.Itoa(buf.body.Len()))
+	w.ResponseWriter.WriteHeader(buf.status)
+	_, _ = w.ResponseWriter.Write(buf.body.Bytes())
+	w.ResponseWriter.Flush()
+	return true
+}
+
+// commit 将处理器的响应头写出到底层写入器，调用方需持有锁
+func (w *timeoutWriter) commit() bool {
+	if w.timedOut {
+		return false
+	}
+	if !w.committed {
+		dst := w.ResponseWriter.Header()
+		for k, v := range w.header {
+			dst[k] = v
+		}
+		w.ResponseWriter.WriteHeader(w.status)
+		w.committed = true
+	}
+	return true
+}
+
+// Header 处理器可见的响应头
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader 记录状态码，实际写出延迟到首次写入响应体
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.committed && !w.timedOut {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow 立即写出响应头
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.commit() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Write 写入响应体，超时后返回 http.ErrHandlerTimeout
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.commit() {
+		return 0, http.ErrHandlerTimeout
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteString 写入字符串响应体
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.commit() {
+		return 0, http.ErrHandlerTimeout
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush 刷新响应
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.commit() {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// Hijack 接管连接（如 WebSocket 升级），接管后不再写出超时响应
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+	w.committed = true
+	return w.ResponseWriter.Hijack()
+}
+
+// Status 返回响应状态码
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.committed || w.timedOut {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+// Size 返回已写出的响应体大小
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.ResponseWriter.Size()
+}
+
+// Written 是否已写出响应
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.committed || w.timedOut
+}
+
+// bufferWriter 将响应缓存在内存中的写入器，用于渲染超时响应
+type bufferWriter struct {
+	gin.ResponseWriter
+	header  http.Header
+	status  int
+	body    bytes.Buffer
+	written bool
+}
+
+func (w *bufferWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.body.Write(b)
+}
+
+func (w *bufferWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferWriter) Flush() {}
+
+func (w *bufferWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferWriter) Written() bool {
+	return w.written
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTimeoutStatusOnlyResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	var recorded int
+	r.Use(func(c *gin.Context) {
+		c.Next()
+		recorded = c.Writer.Status()
+	})
+	r.Use(Timeout(&TimeoutConfig{Timeout: time.Second}))
+	r.DELETE("/items/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r.POST("/items", func(c *gin.Context) {
+		c.Header("Location", "/items/1")
+		c.Status(http.StatusCreated)
+	})
+	r.POST("/login", func(c *gin.Context) {
+		c.Redirect(http.StatusFound, "/home")
+	})
+
+	tests := []struct {
+		method, path string
+		status       int
+		location     string
+	}{
+		{http.MethodDelete, "/items/1", http.StatusNoContent, ""},
+		{http.MethodPost, "/items", http.StatusCreated, "/items/1"},
+		{http.MethodPost, "/login", http.StatusFound, "/home"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.status {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.path, w.Code, tt.status)
+		}
+		if recorded != tt.status {
+			t.Errorf("%s %s: recorded status = %d, want %d", tt.method, tt.path, recorded, tt.status)
+		}
+		if got := w.Header().Get("Location"); got != tt.location {
+			t.Errorf("%s %s: Location = %q, want %q", tt.method, tt.path, got, tt.location)
+		}
+	}
+}
+
+func TestTimeoutWritesTimeoutResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Timeout(&TimeoutConfig{Timeout: 20 * time.Millisecond}))
+	r.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if w.Code == http.StatusNoContent {
+		t.Fatalf("status = %d, want timeout response", w.Code)
+	}
+	if w.Body.Len() == 0 {
+		t.Fatal("timeout response body is empty")
+	}
+}
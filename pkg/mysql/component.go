@@ -0,0 +1,68 @@
+package mysql
+
+import (
+	"context"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+)
+
+// Component MySQL应用组件
+// 创建时只初始化连接池而不建立连接，Start 时检查连接（失败由应用按退避策略重试），Stop 时关闭连接池
+type Component struct {
+	db     *gorm.DB
+	logger *zhlog.Helper
+}
+
+// NewComponent 创建MySQL组件
+func NewComponent(config *MySQLConfig, logger *zhlog.Helper) (*Component, error) {
+	db, err := gorm.Open(mysql.Open(config.DSN()), &gorm.Config{
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	configurePool(sqlDB)
+
+	return &Component{db: db, logger: logger}, nil
+}
+
+// Name 组件名称
+func (c *Component) Name() string {
+	return "mysql"
+}
+
+// Start 检查数据库连接
+func (c *Component) Start(ctx context.Context) error {
+	sqlDB, err := c.db.DB()
+	if err != nil {
+		return err
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return err
+	}
+
+	c.logger.Info("MySQL 数据库连接成功")
+	return nil
+}
+
+// Stop 关闭连接池
+func (c *Component) Stop(ctx context.Context) error {
+	sqlDB, err := c.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// DB 返回 GORM 实例，应用启动前即可获取，连接在首次使用时建立
+func (c *Component) DB() *gorm.DB {
+	return c.db
+}
@@ -1,6 +1,8 @@
 package mysql
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -8,6 +10,8 @@ import (
 	"gorm.io/gorm"
 
 	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/health"
 )
 
 type MySQLConfig struct {
@@ -19,8 +23,7 @@ type MySQLConfig struct {
 }
 
 func NewMySQL(config *MySQLConfig, logger *zhlog.Helper) *gorm.DB {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local", config.User, config.Password, config.Host, config.Port, config.DBName)
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(mysql.Open(config.DSN()), &gorm.Config{})
 	if err != nil {
 		panic(err)
 	}
@@ -33,10 +36,7 @@ func NewMySQL(config *MySQLConfig, logger *zhlog.Helper) *gorm.DB {
 	}
 
 	// 配置连接池
-	sqlDB.SetMaxIdleConns(10)                  // 最大空闲连接数
-	sqlDB.SetMaxOpenConns(100)                 // 最大打开连接数
-	sqlDB.SetConnMaxLifetime(time.Hour)        // 连接最大生存时间
-	sqlDB.SetConnMaxIdleTime(30 * time.Minute) // 连接最大空闲时间
+	configurePool(sqlDB)
 
 	// 检查数据库连接
 	if err := sqlDB.Ping(); err != nil {
@@ -47,3 +47,32 @@ func NewMySQL(config *MySQLConfig, logger *zhlog.Helper) *gorm.DB {
 	logger.Info("MySQL 数据库连接成功")
 	return db
 }
+
+// DSN 返回数据库连接字符串
+func (c *MySQLConfig) DSN() string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local", c.User, c.Password, c.Host, c.Port, c.DBName)
+}
+
+// configurePool 配置连接池
+func configurePool(sqlDB *sql.DB) {
+	sqlDB.SetMaxIdleConns(10)                  // 最大空闲连接数
+	sqlDB.SetMaxOpenConns(100)                 // 最大打开连接数
+	sqlDB.SetConnMaxLifetime(time.Hour)        // 连接最大生存时间
+	sqlDB.SetConnMaxIdleTime(30 * time.Minute) // 连接最大空闲时间
+}
+
+// HealthCheck 返回MySQL健康检查项，数据库为关键依赖
+func HealthCheck(db *gorm.DB) health.Check {
+	return health.Check{
+		Name:     "mysql",
+		Timeout:  2 * time.Second,
+		Critical: true,
+		Checker: health.CheckerFunc(func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		}),
+	}
+}
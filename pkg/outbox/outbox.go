@@ -0,0 +1,169 @@
+package outbox
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"gorm.io/gorm"
+
+	"go-template/pkg/repository"
+)
+
+// 发件箱事件状态
+const (
+	StatusPending   = 0 // 待投递
+	StatusPublished = 1 // 已投递
+	StatusDead      = 2 // 超过最大尝试次数，进入死信
+)
+
+// Event 发件箱事件，对应 outbox_events 表
+type Event struct {
+	ID            uint64    `gorm:"primarykey"`
+	Topic         string    `gorm:"size:255;not null"`
+	EventKey      string    `gorm:"size:255;not null;default:''"`
+	Payload       []byte    `gorm:"not null"`
+	Headers       Headers   `gorm:"type:json"`
+	Status        int       `gorm:"not null;default:0"`
+	Attempts      int       `gorm:"not null;default:0"`
+	LastError     string    `gorm:"size:1024;not null;default:''"`
+	NextAttemptAt time.Time `gorm:"not null"`
+	CreatedAt     time.Time `gorm:"not null"`
+	PublishedAt   *time.Time
+}
+
+// TableName 指定表名
+func (Event) TableName() string {
+	return "outbox_events"
+}
+
+// Headers 事件头，以 JSON 保存
+type Headers map[string]string
+
+// Value 实现 driver.Valuer
+func (h Headers) Value() (driver.Value, error) {
+	if h == nil {
+		return nil, nil
+	}
+	return json.Marshal(h)
+}
+
+// Scan 实现 sql.Scanner
+func (h *Headers) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*h = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, h)
+	case string:
+		return json.Unmarshal([]byte(v), h)
+	default:
+		return fmt.Errorf("outbox: unsupported headers type %T", value)
+	}
+}
+
+// Message 投递给消息系统的事件
+// 投递语义为至少一次，消费者应使用 ID 去重
+type Message struct {
+	ID        uint64            // 事件ID，单调递增
+	Topic     string            // 事件主题
+	Key       string            // 分区键，相同键的事件由支持分区的消息系统路由到同一分区
+	Payload   []byte            // 事件内容
+	Headers   map[string]string // 事件头，包含链路追踪上下文
+	CreatedAt time.Time         // 入队时间
+}
+
+// Sink 事件投递目标
+// 内置 Redis Streams 实现，接入 Kafka、NATS 等消息系统时实现该接口即可
+type Sink interface {
+	// Name 投递目标名称，用于日志
+	Name() string
+	// Publish 投递一条事件，返回 nil 表示消息系统已确认接收
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// Outbox 事务性发件箱
+// 事件与业务数据写入同一个数据库事务，由 Relay 异步投递，避免进程在写库和发消息之间退出导致事件丢失
+type Outbox struct {
+	db *gorm.DB
+}
+
+// New 创建事务性发件箱
+func New(db *gorm.DB) *Outbox {
+	return &Outbox{db: db}
+}
+
+// Enqueue 将事件写入发件箱，payload 为 []byte 时原样保存，否则编码为 JSON
+// 在 TxManager.WithinTx 中调用时与业务数据写入同一事务，事务回滚时事件一并丢弃
+func (o *Outbox) Enqueue(ctx context.Context, topic, key string, payload interface{}) error {
+	return o.EnqueueTx(repository.DB(ctx, o.db), topic, key, payload)
+}
+
+// EnqueueTx 使用调用方持有的事务写入事件
+func (o *Outbox) EnqueueTx(tx *gorm.DB, topic, key string, payload interface{}) error {
+	if topic == "" {
+		return errors.New("outbox: topic is empty")
+	}
+
+	data, ok := payload.([]byte)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("outbox: encode payload: %w", err)
+		}
+	}
+
+	// 保存链路追踪上下文，消费者可以据此延续调用链
+	carrier := propagation.MapCarrier{}
+	if ctx := tx.Statement.Context; ctx != nil {
+		otel.GetTextMapPropagator().Inject(ctx, carrier)
+	}
+	var headers Headers
+	if len(carrier) > 0 {
+		headers = Headers(carrier)
+	}
+
+	now := time.Now()
+	return tx.Create(&Event{
+		Topic:         topic,
+		EventKey:      key,
+		Payload:       data,
+		Headers:       headers,
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}).Error
+}
+
+// Requeue 将死信事件重新放回待投递队列
+func (o *Outbox) Requeue(ctx context.Context, ids ...uint64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := repository.DB(ctx, o.db).Model(&Event{}).
+		Where("id IN ? AND status = ?", ids, StatusDead).
+		Updates(map[string]interface{}{
+			"status":          StatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// message 转换为投递消息
+func (e *Event) message() *Message {
+	return &Message{
+		ID:        e.ID,
+		Topic:     e.Topic,
+		Key:       e.EventKey,
+		Payload:   e.Payload,
+		Headers:   e.Headers,
+		CreatedAt: e.CreatedAt,
+	}
+}
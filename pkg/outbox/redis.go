@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStreamConfig Redis Streams 投递配置
+type RedisStreamConfig struct {
+	StreamPrefix string `toml:"stream_prefix"` // 流名称前缀，流名称为 前缀+主题，默认 outbox:
+	MaxLen       int64  `toml:"max_len"`       // 流的近似最大长度，0 表示不限制
+}
+
+// RedisStreamSink 将事件投递到 Redis Streams，每个主题对应一个流
+// 消息字段：id、topic、key、payload、headers（JSON）、created_at（Unix 毫秒）
+type RedisStreamSink struct {
+	client *redis.Client
+	config *RedisStreamConfig
+}
+
+// NewRedisStreamSink 创建 Redis Streams 投递目标
+func NewRedisStreamSink(client *redis.Client, config *RedisStreamConfig) *RedisStreamSink {
+	if config.StreamPrefix == "" {
+		config.StreamPrefix = "outbox:"
+	}
+	return &RedisStreamSink{client: client, config: config}
+}
+
+// Name 投递目标名称
+func (s *RedisStreamSink) Name() string {
+	return "redis-stream"
+}
+
+// Publish 使用 XADD 写入事件
+func (s *RedisStreamSink) Publish(ctx context.Context, msg *Message) error {
+	headers, err := json.Marshal(msg.Headers)
+	if err != nil {
+		return err
+	}
+
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.Stream(msg.Topic),
+		MaxLen: s.config.MaxLen,
+		Approx: s.config.MaxLen > 0,
+		Values: map[string]interface{}{
+			"id":         strconv.FormatUint(msg.ID, 10),
+			"topic":      msg.Topic,
+			"key":        msg.Key,
+			"payload":    msg.Payload,
+			"headers":    headers,
+			"created_at": msg.CreatedAt.UnixMilli(),
+		},
+	}).Err()
+}
+
+// Stream 返回主题对应的流名称
+func (s *RedisStreamSink) Stream(topic string) string {
+	return s.config.StreamPrefix + topic
+}
@@ -0,0 +1,325 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/prometheus"
+)
+
+// maxErrorLength last_error 列的最大长度
+const maxErrorLength = 1024
+
+// cleanupBatchSize 每次清理的已投递事件数
+const cleanupBatchSize = 1000
+
+// RelayConfig 发件箱投递配置
+type RelayConfig struct {
+	PollInterval   time.Duration       // 轮询间隔，默认 1s
+	BatchSize      int                 // 每批投递的事件数，默认 100
+	MaxAttempts    int                 // 最大尝试次数，超过后进入死信，默认 10
+	InitialBackoff time.Duration       // 首次重试等待时间，默认 1s
+	MaxBackoff     time.Duration       // 最大重试等待时间，默认 5m
+	PublishTimeout time.Duration       // 单条事件投递超时时间，默认 5s
+	LeaseTimeout   time.Duration       // 领取事件的租约时间，超时仍未写回结果的事件会被重新领取，默认 BatchSize × PublishTimeout + 30s
+	Retention      time.Duration       // 已投递事件保留时间，默认 7 天，负数表示不清理
+	Metrics        *prometheus.Metrics // 投递结果和积压指标，可为 nil
+}
+
+// Relay 发件箱投递器，实现 app.Component
+// 轮询待投递事件并投递到 Sink，投递成功后才标记为已投递，因此保证至少一次投递；
+// 使用 SELECT ... FOR UPDATE SKIP LOCKED 领取事件并设置租约，多个实例可以同时运行。
+// 失败的事件按指数退避重试，不保证同一分区键的事件严格有序
+type Relay struct {
+	db     *gorm.DB
+	sink   Sink
+	config *RelayConfig
+	logger *zhlog.Helper
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRelay 创建发件箱投递器
+func NewRelay(db *gorm.DB, sink Sink, config *RelayConfig, logger *zhlog.Helper) *Relay {
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 10
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 5 * time.Minute
+	}
+	if config.PublishTimeout <= 0 {
+		config.PublishTimeout = 5 * time.Second
+	}
+	if config.LeaseTimeout <= 0 {
+		config.LeaseTimeout = time.Duration(config.BatchSize)*config.PublishTimeout + 30*time.Second
+	}
+	if config.Retention == 0 {
+		config.Retention = 7 * 24 * time.Hour
+	}
+
+	return &Relay{
+		db:     db,
+		sink:   sink,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Name 组件名称
+func (r *Relay) Name() string {
+	return "outbox-relay"
+}
+
+// Start 在后台开始投递
+func (r *Relay) Start(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go r.run(runCtx)
+
+	r.logger.Info("发件箱投递器已启动", "sink", r.sink.Name())
+	return nil
+}
+
+// Stop 停止领取新事件，并等待当前批次投递完成
+func (r *Relay) Stop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run 投递循环：批次已满时立即领取下一批，否则等待下一个轮询周期
+func (r *Relay) run(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	var lastCleanup time.Time
+	for {
+		n, err := r.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Error("发件箱投递失败", "error", err)
+		}
+		r.observeBacklog(ctx)
+
+		if r.config.Retention > 0 && time.Since(lastCleanup) > time.Hour {
+			if err := r.cleanup(ctx); err != nil && ctx.Err() == nil {
+				r.logger.Warn("清理已投递的发件箱事件失败", "error", err)
+			}
+			lastCleanup = time.Now()
+		}
+
+		if n >= r.config.BatchSize && err == nil {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce 领取并投递一批到期的事件，返回领取的事件数
+// 领取时在短事务中将事件的 next_attempt_at 推迟到租约到期，租约内其他实例不会重复领取；
+// 投递在事务之外进行，结果在第二个短事务中写回。进程在写回前退出时，事件在租约到期后被重新投递
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	events, err := r.claim(ctx)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	// 已领取的批次不随 ctx 取消中断写回，未投递的事件恢复原投递时间，不必等待租约到期
+	batchCtx := context.WithoutCancel(ctx)
+	updates := make([]map[string]interface{}, len(events))
+	for i := range events {
+		if ctx.Err() != nil {
+			updates[i] = map[string]interface{}{"next_attempt_at": events[i].NextAttemptAt}
+			continue
+		}
+		updates[i] = r.deliver(batchCtx, &events[i])
+	}
+	return len(events), r.record(batchCtx, events, updates)
+}
+
+// claim 在短事务中领取一批到期事件并设置租约
+func (r *Relay) claim(ctx context.Context) ([]Event, error) {
+	var events []Event
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked}).
+			Where("status = ? AND next_attempt_at <= ?", StatusPending, time.Now()).
+			Order("id").
+			Limit(r.config.BatchSize).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		ids := make([]uint64, len(events))
+		for i := range events {
+			ids[i] = events[i].ID
+		}
+		return tx.Model(&Event{}).Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(r.config.LeaseTimeout)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// record 在短事务中写回投递结果
+// 租约到期后事件可能已被其他实例重新领取并更新，attempts 不再相同时跳过该事件
+func (r *Relay) record(ctx context.Context, events []Event, updates []map[string]interface{}) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range events {
+			err := tx.Model(&Event{}).
+				Where("id = ? AND status = ? AND attempts = ?", events[i].ID, StatusPending, events[i].Attempts).
+				Updates(updates[i]).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deliver 投递单条事件，返回需要写回的字段
+func (r *Relay) deliver(ctx context.Context, event *Event) map[string]interface{} {
+	publishCtx, cancel := context.WithTimeout(ctx, r.config.PublishTimeout)
+	err := r.sink.Publish(publishCtx, event.message())
+	cancel()
+
+	now := time.Now()
+	attempts := event.Attempts + 1
+	updates := map[string]interface{}{"attempts": attempts}
+
+	result := prometheus.OutboxPublished
+	switch {
+	case err == nil:
+		updates["status"] = StatusPublished
+		updates["published_at"] = now
+		updates["last_error"] = ""
+	case attempts >= r.config.MaxAttempts:
+		result = prometheus.OutboxDead
+		updates["status"] = StatusDead
+		updates["last_error"] = truncate(err.Error(), maxErrorLength)
+		r.logger.Error("发件箱事件超过最大尝试次数，进入死信",
+			"id", event.ID, "topic", event.Topic, "attempts", attempts, "error", err)
+	default:
+		result = prometheus.OutboxRetried
+		backoff := r.backoff(attempts)
+		updates["next_attempt_at"] = now.Add(backoff)
+		updates["last_error"] = truncate(err.Error(), maxErrorLength)
+		r.logger.Warn("发件箱事件投递失败，稍后重试",
+			"id", event.ID, "topic", event.Topic, "attempts", attempts, "backoff", backoff, "error", err)
+	}
+
+	if r.config.Metrics != nil {
+		r.config.Metrics.RecordOutboxEvent(event.Topic, result)
+	}
+	return updates
+}
+
+// backoff 返回第 attempts 次失败后的等待时间
+func (r *Relay) backoff(attempts int) time.Duration {
+	d := r.config.InitialBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= r.config.MaxBackoff {
+			return r.config.MaxBackoff
+		}
+	}
+	return d
+}
+
+// observeBacklog 更新积压指标
+func (r *Relay) observeBacklog(ctx context.Context) {
+	if r.config.Metrics == nil {
+		return
+	}
+
+	var backlog struct {
+		Pending int64
+		Oldest  sql.NullTime
+	}
+	err := r.db.WithContext(ctx).Model(&Event{}).
+		Select("COUNT(*) AS pending, MIN(created_at) AS oldest").
+		Where("status = ?", StatusPending).
+		Scan(&backlog).Error
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			r.logger.Warn("查询发件箱积压失败", "error", err)
+		}
+		return
+	}
+
+	var lag time.Duration
+	if backlog.Oldest.Valid {
+		lag = time.Since(backlog.Oldest.Time)
+	}
+	r.config.Metrics.SetOutboxBacklog(backlog.Pending, lag)
+}
+
+// cleanup 分批删除超过保留时间的已投递事件
+func (r *Relay) cleanup(ctx context.Context) error {
+	before := time.Now().Add(-r.config.Retention)
+	for ctx.Err() == nil {
+		result := r.db.WithContext(ctx).Exec(
+			"DELETE FROM outbox_events WHERE status = ? AND published_at < ? LIMIT ?",
+			StatusPublished, before, cleanupBatchSize)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < cleanupBatchSize {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// truncate 按字符截断过长的错误信息
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
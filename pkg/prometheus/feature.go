@@ -0,0 +1,71 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// featureMetrics 功能开关指标
+type featureMetrics struct {
+	exposures *prometheus.CounterVec
+	updates   *prometheus.CounterVec
+	flags     prometheus.Gauge
+}
+
+// newFeatureMetrics 创建功能开关指标
+func newFeatureMetrics(config *PrometheusConfig) *featureMetrics {
+	m := &featureMetrics{}
+
+	// 功能开关曝光次数（每个请求每个开关只记录一次）
+	m.exposures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "feature_flag_exposures_total",
+			Help:      "Total number of feature flag evaluations by flag, result and reason.",
+		},
+		[]string{"flag", "enabled", "reason"},
+	)
+
+	// 功能开关配置更新次数
+	m.updates = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "feature_flag_updates_total",
+			Help:      "Total number of feature flag updates received from the store.",
+		},
+		[]string{"flag"},
+	)
+
+	// 当前加载的功能开关数量
+	m.flags = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "feature_flags_loaded",
+			Help:      "Number of feature flags currently loaded.",
+		},
+	)
+
+	return m
+}
+
+// collectors 返回需要注册的指标
+func (m *featureMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.exposures, m.updates, m.flags}
+}
+
+// RecordFeatureExposure 记录一次功能开关曝光，reason 为判定依据（如 rule、rollout、disabled）
+func (m *Metrics) RecordFeatureExposure(flag string, enabled bool, reason string) {
+	result := "false"
+	if enabled {
+		result = "true"
+	}
+	m.feature.exposures.WithLabelValues(flag, result, reason).Inc()
+}
+
+// RecordFeatureUpdate 记录一次功能开关配置更新，并更新已加载的开关数量
+func (m *Metrics) RecordFeatureUpdate(flag string, loaded int) {
+	m.feature.updates.WithLabelValues(flag).Inc()
+	m.feature.flags.Set(float64(loaded))
+}
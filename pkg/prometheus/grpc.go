@@ -0,0 +1,144 @@
+package prometheus
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// gRPC 调用类型
+const (
+	GRPCUnary        = "unary"         // 一元调用
+	GRPCClientStream = "client_stream" // 客户端流
+	GRPCServerStream = "server_stream" // 服务端流
+	GRPCBidiStream   = "bidi_stream"   // 双向流
+)
+
+// grpcMetrics gRPC 服务端指标
+type grpcMetrics struct {
+	started  *prometheus.CounterVec
+	handled  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	panics   *prometheus.CounterVec
+}
+
+// newGRPCMetrics 创建 gRPC 服务端指标
+func newGRPCMetrics(config *PrometheusConfig) *grpcMetrics {
+	m := &grpcMetrics{}
+
+	// 开始处理的调用次数
+	m.started = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "grpc_server_started_total",
+			Help:      "Total number of RPCs started on the server.",
+		},
+		[]string{"type", "service", "method"},
+	)
+
+	// 处理完成的调用次数
+	m.handled = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "grpc_server_handled_total",
+			Help:      "Total number of RPCs completed on the server by status code.",
+		},
+		[]string{"type", "service", "method", "code"},
+	)
+
+	// 调用处理时间
+	m.duration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "grpc_server_handling_seconds",
+			Help:      "RPC handling latencies in seconds.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"type", "service", "method"},
+	)
+
+	// 调用处理 panic 次数
+	m.panics = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "grpc_server_panics_total",
+			Help:      "Total number of panics recovered while handling RPCs.",
+		},
+		[]string{"service", "method"},
+	)
+
+	return m
+}
+
+// collectors 返回需要注册的指标
+func (m *grpcMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.started, m.handled, m.duration, m.panics}
+}
+
+// observe 记录一次调用的开始，返回的函数在调用结束时记录状态码和耗时
+func (m *grpcMetrics) observe(typ, fullMethod string) func(error) {
+	service, method := splitMethodName(fullMethod)
+	m.started.WithLabelValues(typ, service, method).Inc()
+	start := time.Now()
+
+	return func(err error) {
+		code := status.Code(err).String()
+		m.handled.WithLabelValues(typ, service, method, code).Inc()
+		m.duration.WithLabelValues(typ, service, method).Observe(time.Since(start).Seconds())
+	}
+}
+
+// UnaryServerInterceptor 返回gRPC一元调用拦截器用于收集调用指标
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		done := m.grpc.observe(GRPCUnary, info.FullMethod)
+		resp, err := handler(ctx, req)
+		done(err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor 返回gRPC流式调用拦截器用于收集调用指标
+func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		done := m.grpc.observe(streamType(info), info.FullMethod)
+		err := handler(srv, ss)
+		done(err)
+		return err
+	}
+}
+
+// RecordGRPCPanic 记录一次gRPC调用处理panic
+func (m *Metrics) RecordGRPCPanic(fullMethod string) {
+	service, method := splitMethodName(fullMethod)
+	m.grpc.panics.WithLabelValues(service, method).Inc()
+}
+
+// streamType 返回流式调用的类型
+func streamType(info *grpc.StreamServerInfo) string {
+	switch {
+	case info.IsClientStream && info.IsServerStream:
+		return GRPCBidiStream
+	case info.IsClientStream:
+		return GRPCClientStream
+	default:
+		return GRPCServerStream
+	}
+}
+
+// splitMethodName 将 /package.Service/Method 拆分为服务名和方法名
+func splitMethodName(fullMethod string) (string, string) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return "unknown", "unknown"
+	}
+	return service, method
+}
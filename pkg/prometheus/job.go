@@ -0,0 +1,113 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 后台任务处理结果
+const (
+	JobSucceeded = "succeeded" // 处理成功
+	JobRetried   = "retried"   // 处理失败，等待重试
+	JobDead      = "dead"      // 超过最大重试次数或不可重试，进入死信
+)
+
+// jobMetrics 后台任务队列指标
+type jobMetrics struct {
+	enqueued  *prometheus.CounterVec
+	processed *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+	inFlight  *prometheus.GaugeVec
+	claimed   *prometheus.CounterVec
+}
+
+// newJobMetrics 创建后台任务队列指标
+func newJobMetrics(config *PrometheusConfig) *jobMetrics {
+	m := &jobMetrics{}
+
+	// 入队任务数
+	m.enqueued = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "jobs_enqueued_total",
+			Help:      "Total number of background jobs enqueued.",
+		},
+		[]string{"queue", "type"},
+	)
+
+	// 处理任务数
+	m.processed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "jobs_processed_total",
+			Help:      "Total number of background jobs processed by queue, type and result.",
+		},
+		[]string{"queue", "type", "result"},
+	)
+
+	// 任务处理耗时
+	m.duration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "job_duration_seconds",
+			Help:      "Background job processing latencies in seconds.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 4, 10),
+		},
+		[]string{"queue", "type"},
+	)
+
+	// 正在处理的任务数
+	m.inFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "jobs_in_flight",
+			Help:      "Number of background jobs currently being processed.",
+		},
+		[]string{"queue"},
+	)
+
+	// 超过可见性超时被重新领取的任务数
+	m.claimed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "jobs_reclaimed_total",
+			Help:      "Total number of stuck background jobs reclaimed after the visibility timeout.",
+		},
+		[]string{"queue"},
+	)
+
+	return m
+}
+
+// collectors 返回需要注册的指标
+func (m *jobMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.enqueued, m.processed, m.duration, m.inFlight, m.claimed}
+}
+
+// RecordJobEnqueued 记录一次任务入队
+func (m *Metrics) RecordJobEnqueued(queue, jobType string) {
+	m.job.enqueued.WithLabelValues(queue, jobType).Inc()
+}
+
+// RecordJobStarted 记录任务开始处理
+func (m *Metrics) RecordJobStarted(queue string) {
+	m.job.inFlight.WithLabelValues(queue).Inc()
+}
+
+// RecordJobFinished 记录任务处理结果和耗时，result 取值见 JobSucceeded 等常量
+func (m *Metrics) RecordJobFinished(queue, jobType, result string, duration time.Duration) {
+	m.job.inFlight.WithLabelValues(queue).Dec()
+	m.job.processed.WithLabelValues(queue, jobType, result).Inc()
+	m.job.duration.WithLabelValues(queue, jobType).Observe(duration.Seconds())
+}
+
+// RecordJobsReclaimed 记录重新领取的超时任务数
+func (m *Metrics) RecordJobsReclaimed(queue string, n int) {
+	m.job.claimed.WithLabelValues(queue).Add(float64(n))
+}
@@ -0,0 +1,75 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 发件箱事件投递结果
+const (
+	OutboxPublished = "published" // 投递成功
+	OutboxRetried   = "retried"   // 投递失败，等待重试
+	OutboxDead      = "dead"      // 超过最大尝试次数，进入死信
+)
+
+// outboxMetrics 事务性发件箱指标
+type outboxMetrics struct {
+	events  *prometheus.CounterVec
+	pending prometheus.Gauge
+	lag     prometheus.Gauge
+}
+
+// newOutboxMetrics 创建发件箱指标
+func newOutboxMetrics(config *PrometheusConfig) *outboxMetrics {
+	m := &outboxMetrics{}
+
+	// 发件箱事件投递次数
+	m.events = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "outbox_events_total",
+			Help:      "Total number of outbox delivery attempts by topic and result.",
+		},
+		[]string{"topic", "result"},
+	)
+
+	// 待投递事件数
+	m.pending = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "outbox_pending_events",
+			Help:      "Number of outbox events waiting to be published.",
+		},
+	)
+
+	// 投递延迟：最早一条待投递事件的等待时间
+	m.lag = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "outbox_relay_lag_seconds",
+			Help:      "Age of the oldest outbox event that has not been published yet.",
+		},
+	)
+
+	return m
+}
+
+// collectors 返回需要注册的指标
+func (m *outboxMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.events, m.pending, m.lag}
+}
+
+// RecordOutboxEvent 记录一次发件箱事件投递结果，result 取值见 OutboxPublished 等常量
+func (m *Metrics) RecordOutboxEvent(topic, result string) {
+	m.outbox.events.WithLabelValues(topic, result).Inc()
+}
+
+// SetOutboxBacklog 更新发件箱积压情况：待投递事件数以及最早一条待投递事件的等待时间
+func (m *Metrics) SetOutboxBacklog(pending int64, lag time.Duration) {
+	m.outbox.pending.Set(float64(pending))
+	m.outbox.lag.Set(lag.Seconds())
+}
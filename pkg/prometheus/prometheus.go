@@ -14,9 +14,10 @@ import (
 
 // PrometheusConfig Prometheus配置
 type PrometheusConfig struct {
-	Namespace   string // 命名空间
-	Subsystem   string // 子系统
-	MetricsPath string // 指标路径，默认 /metrics
+	Namespace   string      // 命名空间
+	Subsystem   string      // 子系统
+	MetricsPath string      // 指标路径，默认 /metrics
+	SLOs        []SLOConfig // 服务等级目标
 }
 
 // Metrics Prometheus指标集合
@@ -26,23 +27,41 @@ type Metrics struct {
 	responseSize    *prometheus.HistogramVec
 	requestSize     *prometheus.HistogramVec
 	uptime          prometheus.Counter
+	timeoutsTotal   *prometheus.CounterVec
+	panicsTotal     *prometheus.CounterVec
+	slo             *sloMetrics
+	outbox          *outboxMetrics
+	job             *jobMetrics
+	scheduler       *schedulerMetrics
+	feature         *featureMetrics
+	websocket       *websocketMetrics
+	sse             *sseMetrics
+	grpc            *grpcMetrics
 	registry        *prometheus.Registry
 	config          *PrometheusConfig
 	logger          *zhlog.Helper
 }
 
-// NewMetrics 创建Prometheus指标收集器
-func NewMetrics(config *PrometheusConfig, logger *zhlog.Helper) *Metrics {
+// NewMetrics 创建Prometheus指标收集器，SLO 配置无效时返回错误
+func NewMetrics(config *PrometheusConfig, logger *zhlog.Helper) (*Metrics, error) {
 	if config.MetricsPath == "" {
 		config.MetricsPath = "/metrics"
 	}
 
+	// SLO指标，先于其他指标创建以便配置无效时尽早返回
+	slo, err := newSLOMetrics(config)
+	if err != nil {
+		logger.Error("SLO 配置无效", "error", err)
+		return nil, err
+	}
+
 	registry := prometheus.NewRegistry()
 
 	metrics := &Metrics{
 		registry: registry,
 		config:   config,
 		logger:   logger,
+		slo:      slo,
 	}
 
 	// HTTP请求总数
@@ -102,6 +121,37 @@ func NewMetrics(config *PrometheusConfig, logger *zhlog.Helper) *Metrics {
 		},
 	)
 
+	// HTTP请求超时次数
+	metrics.timeoutsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "http_request_timeouts_total",
+			Help:      "Total number of HTTP requests that exceeded their deadline.",
+		},
+		[]string{"method", "path"},
+	)
+
+	// HTTP请求处理panic次数
+	metrics.panicsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "http_panics_total",
+			Help:      "Total number of panics recovered while handling HTTP requests.",
+		},
+		[]string{"method", "path"},
+	)
+
+	// 业务组件指标
+	metrics.outbox = newOutboxMetrics(config)
+	metrics.job = newJobMetrics(config)
+	metrics.scheduler = newSchedulerMetrics(config)
+	metrics.feature = newFeatureMetrics(config)
+	metrics.websocket = newWebSocketMetrics(config)
+	metrics.sse = newSSEMetrics(config)
+	metrics.grpc = newGRPCMetrics(config)
+
 	// 注册指标
 	registry.MustRegister(
 		metrics.requestsTotal,
@@ -109,13 +159,24 @@ func NewMetrics(config *PrometheusConfig, logger *zhlog.Helper) *Metrics {
 		metrics.responseSize,
 		metrics.requestSize,
 		metrics.uptime,
+		metrics.timeoutsTotal,
+		metrics.panicsTotal,
+		metrics.slo.total,
+		metrics.slo.good,
 	)
+	registry.MustRegister(metrics.outbox.collectors()...)
+	registry.MustRegister(metrics.job.collectors()...)
+	registry.MustRegister(metrics.scheduler.collectors()...)
+	registry.MustRegister(metrics.feature.collectors()...)
+	registry.MustRegister(metrics.websocket.collectors()...)
+	registry.MustRegister(metrics.sse.collectors()...)
+	registry.MustRegister(metrics.grpc.collectors()...)
 
 	// 启动uptime计数器
 	go metrics.startUptimeCounter()
 
 	logger.Info("Prometheus metrics initialized", "namespace", config.Namespace, "subsystem", config.Subsystem)
-	return metrics
+	return metrics, nil
 }
 
 // startUptimeCounter 启动uptime计数器
@@ -150,7 +211,8 @@ func (m *Metrics) GinMiddleware() gin.HandlerFunc {
 		c.Next()
 
 		// 计算响应时间
-		duration := time.Since(start).Seconds()
+		elapsed := time.Since(start)
+		duration := elapsed.Seconds()
 		statusCode := strconv.Itoa(c.Writer.Status())
 
 		// 记录指标
@@ -171,9 +233,22 @@ func (m *Metrics) GinMiddleware() gin.HandlerFunc {
 			c.FullPath(),
 			statusCode,
 		).Observe(float64(c.Writer.Size()))
+
+		// 记录SLO
+		m.slo.observe(c.Request.Method, c.FullPath(), c.Writer.Status(), elapsed)
 	}
 }
 
+// RecordTimeout 记录一次请求超时
+func (m *Metrics) RecordTimeout(method, path string) {
+	m.timeoutsTotal.WithLabelValues(method, path).Inc()
+}
+
+// RecordPanic 记录一次请求处理panic
+func (m *Metrics) RecordPanic(method, path string) {
+	m.panicsTotal.WithLabelValues(method, path).Inc()
+}
+
 // Handler 返回Prometheus指标处理器
 func (m *Metrics) Handler() http.Handler {
 	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
@@ -0,0 +1,97 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 定时任务执行结果
+const (
+	ScheduleSucceeded = "succeeded" // 执行成功
+	ScheduleFailed    = "failed"    // 执行失败（包括 panic）
+	ScheduleTimeout   = "timeout"   // 执行超时
+	ScheduleSkipped   = "skipped"   // 上一次执行尚未结束，按重叠策略跳过
+)
+
+// schedulerMetrics 定时任务指标
+type schedulerMetrics struct {
+	runs        *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	nextRun     *prometheus.GaugeVec
+	lastSuccess *prometheus.GaugeVec
+}
+
+// newSchedulerMetrics 创建定时任务指标
+func newSchedulerMetrics(config *PrometheusConfig) *schedulerMetrics {
+	m := &schedulerMetrics{}
+
+	// 定时任务执行次数
+	m.runs = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "scheduler_runs_total",
+			Help:      "Total number of scheduled job runs by job and result.",
+		},
+		[]string{"job", "result"},
+	)
+
+	// 定时任务执行耗时
+	m.duration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "scheduler_run_duration_seconds",
+			Help:      "Scheduled job run latencies in seconds.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+		},
+		[]string{"job"},
+	)
+
+	// 下次执行时间
+	m.nextRun = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "scheduler_next_run_timestamp_seconds",
+			Help:      "Unix timestamp of the next planned run of a scheduled job.",
+		},
+		[]string{"job"},
+	)
+
+	// 最近一次成功执行的时间，可用于告警长时间未成功的任务
+	m.lastSuccess = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "scheduler_last_success_timestamp_seconds",
+			Help:      "Unix timestamp of the last successful run of a scheduled job.",
+		},
+		[]string{"job"},
+	)
+
+	return m
+}
+
+// collectors 返回需要注册的指标
+func (m *schedulerMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.runs, m.duration, m.nextRun, m.lastSuccess}
+}
+
+// RecordScheduleRun 记录一次定时任务执行结果，result 取值见 ScheduleSucceeded 等常量，跳过的执行 duration 为 0
+func (m *Metrics) RecordScheduleRun(job, result string, duration time.Duration) {
+	m.scheduler.runs.WithLabelValues(job, result).Inc()
+	if result == ScheduleSkipped {
+		return
+	}
+	m.scheduler.duration.WithLabelValues(job).Observe(duration.Seconds())
+	if result == ScheduleSucceeded {
+		m.scheduler.lastSuccess.WithLabelValues(job).Set(float64(time.Now().Unix()))
+	}
+}
+
+// SetScheduleNextRun 更新定时任务的下次执行时间
+func (m *Metrics) SetScheduleNextRun(job string, next time.Time) {
+	m.scheduler.nextRun.WithLabelValues(job).Set(float64(next.Unix()))
+}
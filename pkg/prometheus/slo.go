@@ -0,0 +1,297 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+// SLI 类型
+const (
+	SLIAvailability = "availability" // 可用性：非5xx响应视为成功
+	SLILatency      = "latency"      // 延迟：响应时间不超过阈值视为成功
+)
+
+// SLOConfig 服务等级目标配置
+type SLOConfig struct {
+	Name             string  `toml:"name"`              // SLO名称，作为指标标签
+	Route            string  `toml:"route"`             // 路由模板，与 gin 的 FullPath 一致，如 /api/v1/users/:id
+	Method           string  `toml:"method"`            // HTTP方法，为空时匹配所有方法
+	Objective        float64 `toml:"objective"`         // 目标值 (0.0-1.0)，如 0.999
+	LatencyThreshold string  `toml:"latency_threshold"` // 延迟阈值，如 "300ms"；为空时只统计可用性
+	Window           string  `toml:"window"`            // 统计窗口，如 "30d"，默认 30d
+
+	latencyThreshold time.Duration
+}
+
+// sloMetrics SLO指标
+type sloMetrics struct {
+	total *prometheus.CounterVec
+	good  *prometheus.CounterVec
+	slos  map[string][]*SLOConfig // key: 路由模板
+}
+
+// Validate 校验SLO配置
+func (s *SLOConfig) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("slo name is required")
+	}
+	if s.Route == "" {
+		return fmt.Errorf("slo %s: route is required", s.Name)
+	}
+	if s.Objective <= 0 || s.Objective >= 1 {
+		return fmt.Errorf("slo %s: objective must be between 0 and 1, got %v", s.Name, s.Objective)
+	}
+	if s.LatencyThreshold != "" {
+		threshold, err := time.ParseDuration(s.LatencyThreshold)
+		if err != nil {
+			return fmt.Errorf("slo %s: invalid latency_threshold: %w", s.Name, err)
+		}
+		s.latencyThreshold = threshold
+	}
+	if s.Window == "" {
+		s.Window = "30d"
+	}
+	if _, err := model.ParseDuration(s.Window); err != nil {
+		return fmt.Errorf("slo %s: invalid window: %w", s.Name, err)
+	}
+	return nil
+}
+
+// SLIs 返回该SLO统计的SLI类型
+func (s *SLOConfig) SLIs() []string {
+	if s.LatencyThreshold == "" {
+		return []string{SLIAvailability}
+	}
+	return []string{SLIAvailability, SLILatency}
+}
+
+// newSLOMetrics 创建SLO指标
+func newSLOMetrics(config *PrometheusConfig) (*sloMetrics, error) {
+	m := &sloMetrics{
+		slos: make(map[string][]*SLOConfig),
+	}
+
+	for i := range config.SLOs {
+		slo := &config.SLOs[i]
+		if err := slo.Validate(); err != nil {
+			return nil, err
+		}
+		m.slos[slo.Route] = append(m.slos[slo.Route], slo)
+	}
+
+	// SLO请求总数
+	m.total = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "slo_requests_total",
+			Help:      "Total number of requests evaluated against an SLO.",
+		},
+		[]string{"slo", "sli"},
+	)
+
+	// SLO达标请求数
+	m.good = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "slo_good_requests_total",
+			Help:      "Number of requests that met an SLO.",
+		},
+		[]string{"slo", "sli"},
+	)
+
+	// 预先初始化标签，避免告警规则在没有流量时缺失时间序列
+	for _, slos := range m.slos {
+		for _, slo := range slos {
+			for _, sli := range slo.SLIs() {
+				m.total.WithLabelValues(slo.Name, sli)
+				m.good.WithLabelValues(slo.Name, sli)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// observe 记录一次请求的SLI结果
+func (m *sloMetrics) observe(method, route string, statusCode int, duration time.Duration) {
+	for _, slo := range m.slos[route] {
+		if slo.Method != "" && !strings.EqualFold(slo.Method, method) {
+			continue
+		}
+
+		m.total.WithLabelValues(slo.Name, SLIAvailability).Inc()
+		if statusCode < 500 {
+			m.good.WithLabelValues(slo.Name, SLIAvailability).Inc()
+		}
+
+		if slo.latencyThreshold > 0 {
+			m.total.WithLabelValues(slo.Name, SLILatency).Inc()
+			if duration <= slo.latencyThreshold {
+				m.good.WithLabelValues(slo.Name, SLILatency).Inc()
+			}
+		}
+	}
+}
+
+// burnRateWindow 多窗口燃烧率告警配置
+// 参考 Google SRE Workbook: 在长窗口内消耗了 BudgetConsumed 比例的错误预算时告警，
+// 短窗口用于确认问题仍在持续
+type burnRateWindow struct {
+	Long           string
+	Short          string
+	BudgetConsumed float64
+	Severity       string
+}
+
+var burnRateWindows = []burnRateWindow{
+	{Long: "1h", Short: "5m", BudgetConsumed: 0.02, Severity: "page"},
+	{Long: "6h", Short: "30m", BudgetConsumed: 0.05, Severity: "page"},
+	{Long: "1d", Short: "2h", BudgetConsumed: 0.10, Severity: "ticket"},
+	{Long: "3d", Short: "6h", BudgetConsumed: 0.10, Severity: "ticket"},
+}
+
+// sloRuleWindows 需要预先计算错误率的窗口
+var sloRuleWindows = []string{"5m", "30m", "1h", "2h", "6h", "1d", "3d"}
+
+type sloRuleData struct {
+	Name        string
+	SLI         string
+	Route       string
+	Method      string
+	Objective   float64
+	ErrorBudget float64
+	Window      string
+	TotalMetric string
+	GoodMetric  string
+	Windows     []string
+	Alerts      []sloAlertData
+}
+
+type sloAlertData struct {
+	burnRateWindow
+	Factor float64
+}
+
+// GenerateSLORules 根据SLO配置生成Prometheus记录规则和多窗口燃烧率告警规则
+func GenerateSLORules(config *PrometheusConfig) ([]byte, error) {
+	totalMetric := prometheus.BuildFQName(config.Namespace, config.Subsystem, "slo_requests_total")
+	goodMetric := prometheus.BuildFQName(config.Namespace, config.Subsystem, "slo_good_requests_total")
+
+	var rules []sloRuleData
+	for i := range config.SLOs {
+		slo := &config.SLOs[i]
+		if err := slo.Validate(); err != nil {
+			return nil, err
+		}
+
+		window, _ := model.ParseDuration(slo.Window)
+
+		for _, sli := range slo.SLIs() {
+			data := sloRuleData{
+				Name:        slo.Name,
+				SLI:         sli,
+				Route:       slo.Route,
+				Method:      slo.Method,
+				Objective:   slo.Objective,
+				ErrorBudget: roundRatio(1 - slo.Objective),
+				Window:      slo.Window,
+				TotalMetric: totalMetric,
+				GoodMetric:  goodMetric,
+				Windows:     sloRuleWindows,
+			}
+
+			for _, w := range burnRateWindows {
+				long, _ := model.ParseDuration(w.Long)
+				data.Alerts = append(data.Alerts, sloAlertData{
+					burnRateWindow: w,
+					Factor:         roundRatio(w.BudgetConsumed * float64(window) / float64(long)),
+				})
+			}
+
+			rules = append(rules, data)
+		}
+	}
+
+	tmpl, err := template.New("slo_rules").Parse(sloRulesTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, rules); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// roundRatio 去除浮点误差，避免规则中出现 0.0010000000000000009 之类的数值
+func roundRatio(v float64) float64 {
+	return math.Round(v*1e9) / 1e9
+}
+
+// sloRulesTemplate Prometheus规则文件模板
+const sloRulesTemplate = `# 由 tools/slogen 根据配置文件中的 [[monitoring.slo]] 生成，请勿手动修改
+groups:{{if not .}} []{{end}}
+{{- range .}}
+  - name: slo-{{.Name}}-{{.SLI}}
+    rules:
+{{- $r := .}}
+{{- range .Windows}}
+      - record: slo:sli_error:ratio_rate{{.}}
+        expr: |
+          1 - (
+            sum(rate({{$r.GoodMetric}}{slo="{{$r.Name}}",sli="{{$r.SLI}}"}[{{.}}]))
+            /
+            sum(rate({{$r.TotalMetric}}{slo="{{$r.Name}}",sli="{{$r.SLI}}"}[{{.}}]))
+          )
+        labels:
+          slo: {{$r.Name}}
+          sli: {{$r.SLI}}
+{{- end}}
+      - record: slo:objective:ratio
+        expr: vector({{.Objective}})
+        labels:
+          slo: {{.Name}}
+          sli: {{.SLI}}
+      - record: slo:error_budget_remaining:ratio
+        expr: |
+          1 - (
+            (
+              1 - (
+                sum(increase({{.GoodMetric}}{slo="{{.Name}}",sli="{{.SLI}}"}[{{.Window}}]))
+                /
+                sum(increase({{.TotalMetric}}{slo="{{.Name}}",sli="{{.SLI}}"}[{{.Window}}]))
+              )
+            ) / {{printf "%g" .ErrorBudget}}
+          )
+        labels:
+          slo: {{.Name}}
+          sli: {{.SLI}}
+{{- range .Alerts}}
+      - alert: SLOErrorBudgetBurn
+        expr: |
+          slo:sli_error:ratio_rate{{.Long}}{slo="{{$r.Name}}",sli="{{$r.SLI}}"} > ({{printf "%g" .Factor}} * {{printf "%g" $r.ErrorBudget}})
+          and
+          slo:sli_error:ratio_rate{{.Short}}{slo="{{$r.Name}}",sli="{{$r.SLI}}"} > ({{printf "%g" .Factor}} * {{printf "%g" $r.ErrorBudget}})
+        for: 2m
+        labels:
+          severity: {{.Severity}}
+          slo: {{$r.Name}}
+          sli: {{$r.SLI}}
+          long_window: {{.Long}}
+          short_window: {{.Short}}
+        annotations:
+          summary: "SLO {{$r.Name}} ({{$r.SLI}}) 错误预算燃烧过快"
+          description: "{{if $r.Method}}{{$r.Method}} {{end}}{{$r.Route}} 在 {{.Long}} 窗口内的燃烧率超过 {{printf "%g" .Factor}}x，{{$r.Window}} 窗口错误预算将被快速耗尽"
+{{- end}}
+{{- end}}
+`
@@ -0,0 +1,102 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// sseMetrics SSE 指标
+type sseMetrics struct {
+	active      prometheus.Gauge
+	connections *prometheus.CounterVec
+	events      *prometheus.CounterVec
+	replayed    prometheus.Counter
+	lagged      prometheus.Counter
+}
+
+// newSSEMetrics 创建 SSE 指标
+func newSSEMetrics(config *PrometheusConfig) *sseMetrics {
+	m := &sseMetrics{}
+
+	// 当前连接数
+	m.active = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "sse_connections",
+			Help:      "Number of currently open SSE streams.",
+		},
+	)
+
+	// 连接请求次数，result 与 WebSocket 相同（accepted、rejected_auth、rejected_limit）
+	m.connections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "sse_connections_total",
+			Help:      "Total number of SSE connection attempts by result.",
+		},
+		[]string{"result"},
+	)
+
+	// 发送的事件数量
+	m.events = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "sse_events_sent_total",
+			Help:      "Total number of SSE events written to clients by stream.",
+		},
+		[]string{"stream"},
+	)
+
+	// 断线重连时从回放缓冲区补发的事件数量
+	m.replayed = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "sse_replayed_events_total",
+			Help:      "Total number of SSE events replayed from the buffer after Last-Event-ID resume.",
+		},
+	)
+
+	// 因发送缓冲区已满而断开的连接数量
+	m.lagged = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "sse_lagged_disconnections_total",
+			Help:      "Total number of SSE streams closed because the client could not keep up.",
+		},
+	)
+
+	return m
+}
+
+// collectors 返回需要注册的指标
+func (m *sseMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.active, m.connections, m.events, m.replayed, m.lagged}
+}
+
+// RecordSSEConnection 记录一次连接请求，result 为 WSAccepted 时当前连接数加一
+func (m *Metrics) RecordSSEConnection(result string) {
+	m.sse.connections.WithLabelValues(result).Inc()
+	if result == WSAccepted {
+		m.sse.active.Inc()
+	}
+}
+
+// RecordSSEDisconnection 记录一次连接关闭，lagged 表示因客户端消费过慢而断开
+func (m *Metrics) RecordSSEDisconnection(lagged bool) {
+	m.sse.active.Dec()
+	if lagged {
+		m.sse.lagged.Inc()
+	}
+}
+
+// RecordSSEEvent 记录一个写出的事件，replayed 表示从回放缓冲区补发
+func (m *Metrics) RecordSSEEvent(stream string, replayed bool) {
+	m.sse.events.WithLabelValues(stream).Inc()
+	if replayed {
+		m.sse.replayed.Inc()
+	}
+}
@@ -0,0 +1,120 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// WebSocket 连接结果
+const (
+	WSAccepted      = "accepted"       // 连接成功
+	WSRejectedAuth  = "rejected_auth"  // 认证失败
+	WSRejectedLimit = "rejected_limit" // 超出连接数限制
+	WSUpgradeFailed = "upgrade_failed" // 协议升级失败
+)
+
+// WebSocket 消息方向
+const (
+	WSInbound  = "in"  // 客户端发送
+	WSOutbound = "out" // 服务端发送
+)
+
+// WSUnknownType 未注册或无法解析的客户端消息类型，客户端发送的类型不直接作为标签，避免产生无限多的时间序列
+const WSUnknownType = "unknown"
+
+// websocketMetrics WebSocket 指标
+type websocketMetrics struct {
+	active      prometheus.Gauge
+	connections *prometheus.CounterVec
+	closes      *prometheus.CounterVec
+	messages    *prometheus.CounterVec
+	dropped     prometheus.Counter
+}
+
+// newWebSocketMetrics 创建 WebSocket 指标
+func newWebSocketMetrics(config *PrometheusConfig) *websocketMetrics {
+	m := &websocketMetrics{}
+
+	// 当前连接数
+	m.active = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "websocket_connections",
+			Help:      "Number of currently open WebSocket connections.",
+		},
+	)
+
+	// 连接请求次数
+	m.connections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "websocket_connections_total",
+			Help:      "Total number of WebSocket connection attempts by result.",
+		},
+		[]string{"result"},
+	)
+
+	// 连接关闭次数
+	m.closes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "websocket_disconnections_total",
+			Help:      "Total number of closed WebSocket connections by reason.",
+		},
+		[]string{"reason"},
+	)
+
+	// 消息数量
+	m.messages = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "websocket_messages_total",
+			Help:      "Total number of WebSocket messages by direction and type.",
+		},
+		[]string{"direction", "type"},
+	)
+
+	// 发送缓冲区已满而丢弃的消息数量
+	m.dropped = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      "websocket_dropped_messages_total",
+			Help:      "Total number of outbound WebSocket messages dropped because the send buffer was full.",
+		},
+	)
+
+	return m
+}
+
+// collectors 返回需要注册的指标
+func (m *websocketMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.active, m.connections, m.closes, m.messages, m.dropped}
+}
+
+// RecordWSConnection 记录一次连接请求，result 为 WSAccepted 时当前连接数加一
+func (m *Metrics) RecordWSConnection(result string) {
+	m.websocket.connections.WithLabelValues(result).Inc()
+	if result == WSAccepted {
+		m.websocket.active.Inc()
+	}
+}
+
+// RecordWSDisconnection 记录一次连接关闭，当前连接数减一
+func (m *Metrics) RecordWSDisconnection(reason string) {
+	m.websocket.closes.WithLabelValues(reason).Inc()
+	m.websocket.active.Dec()
+}
+
+// RecordWSMessage 记录一条消息，direction 为 WSInbound 或 WSOutbound
+func (m *Metrics) RecordWSMessage(direction, msgType string) {
+	m.websocket.messages.WithLabelValues(direction, msgType).Inc()
+}
+
+// RecordWSDropped 记录一条因发送缓冲区已满而丢弃的消息
+func (m *Metrics) RecordWSDropped() {
+	m.websocket.dropped.Inc()
+}
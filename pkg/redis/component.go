@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+)
+
+// Component Redis应用组件
+// 创建时只初始化客户端，Start 时检查连接（失败由应用按退避策略重试），Stop 时关闭客户端
+type Component struct {
+	client *redis.Client
+	logger *zhlog.Helper
+}
+
+// NewComponent 创建Redis组件
+func NewComponent(config *RedisConfig, logger *zhlog.Helper) *Component {
+	return &Component{
+		client: redis.NewClient(newOptions(config)),
+		logger: logger,
+	}
+}
+
+// Name 组件名称
+func (c *Component) Name() string {
+	return "redis"
+}
+
+// Start 检查Redis连接
+func (c *Component) Start(ctx context.Context) error {
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		return err
+	}
+
+	c.logger.Info("Redis 缓存连接成功")
+	return nil
+}
+
+// Stop 关闭客户端
+func (c *Component) Stop(ctx context.Context) error {
+	return c.client.Close()
+}
+
+// Client 返回Redis客户端
+func (c *Component) Client() *redis.Client {
+	return c.client
+}
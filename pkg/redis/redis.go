@@ -8,6 +8,8 @@ import (
 	"github.com/redis/go-redis/v9"
 
 	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/health"
 )
 
 type RedisConfig struct {
@@ -18,19 +20,7 @@ type RedisConfig struct {
 }
 
 func NewRedis(config *RedisConfig, logger *zhlog.Helper) *redis.Client {
-	redisClient := redis.NewClient(&redis.Options{
-		Addr:         fmt.Sprintf("%s:%s", config.Host, config.Port), // Redis服务器地址
-		Password:     config.Password,                                // Redis密码
-		DB:           config.DB,                                      // 使用默认数据库
-		PoolSize:     100,                                            // 连接池大小
-		MinIdleConns: 10,                                             // 最小空闲连接数
-		MaxIdleConns: 20,                                             // 最大空闲连接数
-		MaxRetries:   3,                                              // 最大重试次数
-		DialTimeout:  5 * time.Second,                                // 连接超时时间
-		ReadTimeout:  3 * time.Second,                                // 读取超时时间
-		WriteTimeout: 3 * time.Second,                                // 写入超时时间
-		PoolTimeout:  4 * time.Second,                                // 连接池超时时间
-	})
+	redisClient := redis.NewClient(newOptions(config))
 
 	// 检查Redis连接
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -45,3 +35,32 @@ func NewRedis(config *RedisConfig, logger *zhlog.Helper) *redis.Client {
 
 	return redisClient
 }
+
+// newOptions 根据配置创建客户端选项
+func newOptions(config *RedisConfig) *redis.Options {
+	return &redis.Options{
+		Addr:         fmt.Sprintf("%s:%s", config.Host, config.Port), // Redis服务器地址
+		Password:     config.Password,                                // Redis密码
+		DB:           config.DB,                                      // 使用默认数据库
+		PoolSize:     100,                                            // 连接池大小
+		MinIdleConns: 10,                                             // 最小空闲连接数
+		MaxIdleConns: 20,                                             // 最大空闲连接数
+		MaxRetries:   3,                                              // 最大重试次数
+		DialTimeout:  5 * time.Second,                                // 连接超时时间
+		ReadTimeout:  3 * time.Second,                                // 读取超时时间
+		WriteTimeout: 3 * time.Second,                                // 写入超时时间
+		PoolTimeout:  4 * time.Second,                                // 连接池超时时间
+	}
+}
+
+// HealthCheck 返回Redis健康检查项，Redis为关键依赖
+func HealthCheck(client *redis.Client) health.Check {
+	return health.Check{
+		Name:     "redis",
+		Timeout:  time.Second,
+		Critical: true,
+		Checker: health.CheckerFunc(func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		}),
+	}
+}
@@ -0,0 +1,108 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/app"
+	"go-template/pkg/consul"
+	"go-template/pkg/discovery"
+	"go-template/pkg/etcd"
+	"go-template/pkg/health"
+	"go-template/pkg/kv"
+)
+
+// 注册中心后端
+const (
+	BackendEtcd   = "etcd"
+	BackendConsul = "consul"
+)
+
+// RegistryConfig 注册中心配置，通过 Backend 在 etcd 和 Consul 之间切换
+type RegistryConfig struct {
+	Backend        string                       // etcd 或 consul，默认 etcd
+	Etcd           etcd.EtcdConfig              // etcd 连接配置
+	EtcdRegistry   discovery.EtcdRegistryConfig // etcd 服务注册配置
+	Consul         consul.ConsulConfig          // Consul 连接配置
+	ConsulRegistry consul.RegistryConfig        // Consul 服务注册配置
+}
+
+// Component 注册中心组件，按配置创建 etcd 或 Consul 客户端，并提供与后端无关的服务注册、服务发现和键值存储
+type Component struct {
+	client    app.Component
+	registry  discovery.Registry
+	discovery discovery.Discovery
+	kv        kv.Store
+	check     health.Check
+}
+
+// NewComponent 按配置的后端创建注册中心组件
+func NewComponent(config *RegistryConfig, logger *zhlog.Helper) (*Component, error) {
+	switch config.Backend {
+	case "", BackendEtcd:
+		component, err := etcd.NewComponent(&config.Etcd, logger)
+		if err != nil {
+			return nil, err
+		}
+		r := discovery.NewEtcdRegistry(component.Client(), &config.EtcdRegistry, logger)
+		return &Component{
+			client:    component,
+			registry:  r,
+			discovery: r,
+			kv:        etcd.NewKVStore(component.Client()),
+			check:     etcd.HealthCheck(component.Client()),
+		}, nil
+	case BackendConsul:
+		component, err := consul.NewComponent(&config.Consul, logger)
+		if err != nil {
+			return nil, err
+		}
+		r := consul.NewRegistry(component.Client(), &config.ConsulRegistry, logger)
+		return &Component{
+			client:    component,
+			registry:  r,
+			discovery: r,
+			kv:        consul.NewKVStore(component.Client()),
+			check:     consul.HealthCheck(component.Client()),
+		}, nil
+	default:
+		return nil, fmt.Errorf("registry: unknown backend %q", config.Backend)
+	}
+}
+
+// Name 组件名称，与后端客户端组件相同（etcd 或 consul），依赖该组件的其他组件无需关心具体后端
+func (c *Component) Name() string {
+	return c.client.Name()
+}
+
+// Start 检查后端是否可用
+func (c *Component) Start(ctx context.Context) error {
+	return c.client.Start(ctx)
+}
+
+// Stop 关闭后端客户端
+func (c *Component) Stop(ctx context.Context) error {
+	return c.client.Stop(ctx)
+}
+
+// Registry 返回服务注册
+func (c *Component) Registry() discovery.Registry {
+	return c.registry
+}
+
+// Discovery 返回服务发现
+func (c *Component) Discovery() discovery.Discovery {
+	return c.discovery
+}
+
+// KV 返回键值存储
+func (c *Component) KV() kv.Store {
+	return c.kv
+}
+
+// HealthCheck 返回后端健康检查项
+func (c *Component) HealthCheck() health.Check {
+	return c.check
+}
@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"go-template/utils/common"
+)
+
+// VersionColumn 乐观锁版本列，模型包含该列时 Update 会校验并递增版本号
+const VersionColumn = "version"
+
+// defaultBatchSize 批量写入的默认批大小
+const defaultBatchSize = 500
+
+// ErrStaleVersion 乐观锁校验失败：记录已被其他请求修改或已删除
+var ErrStaleVersion = errors.New("repository: stale version")
+
+// Model 仓库管理的模型约束，嵌入 model.BaseModel 的类型均满足该约束
+type Model interface {
+	GetID() uint
+}
+
+// Scope 查询条件，与 gorm.DB.Scopes 的参数相同，可直接使用 common.Page 提供的 Scope
+type Scope = func(*gorm.DB) *gorm.DB
+
+// WithDeleted 查询时包含已软删除的记录
+func WithDeleted() Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}
+}
+
+// OnlyDeleted 只查询已软删除的记录
+func OnlyDeleted() Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped().Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: "deleted_at"}, Value: nil})
+	}
+}
+
+// Repository 通用数据仓库，提供增删改查、批量写入、软删除和乐观锁
+// 所有方法优先使用 ctx 中由 TxManager.WithinTx 开启的事务
+type Repository[T Model] struct {
+	db        *gorm.DB
+	versioned bool
+	version   *schema.Field
+}
+
+// NewRepository 创建通用数据仓库，模型包含 version 列时自动启用乐观锁
+func NewRepository[T Model](db *gorm.DB) *Repository[T] {
+	sch, err := schema.Parse(new(T), &sync.Map{}, db.NamingStrategy)
+	if err != nil {
+		panic(fmt.Errorf("repository: parse model %T: %w", *new(T), err))
+	}
+
+	r := &Repository[T]{db: db}
+	if field := sch.LookUpField(VersionColumn); field != nil {
+		switch field.FieldType.Kind() {
+		case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+			r.versioned = true
+			r.version = field
+		}
+	}
+	return r
+}
+
+// DB 返回绑定 ctx 的数据库会话，用于编写仓库未覆盖的查询
+func (r *Repository[T]) DB(ctx context.Context) *gorm.DB {
+	return DB(ctx, r.db)
+}
+
+// Create 创建记录，乐观锁模型的初始版本号为 1
+func (r *Repository[T]) Create(ctx context.Context, entity *T) error {
+	r.initVersion(ctx, entity)
+	return r.DB(ctx).Create(entity).Error
+}
+
+// CreateBatch 分批插入记录，batchSize <= 0 时使用默认批大小
+func (r *Repository[T]) CreateBatch(ctx context.Context, entities []*T, batchSize int) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	for _, entity := range entities {
+		r.initVersion(ctx, entity)
+	}
+	return r.DB(ctx).CreateInBatches(entities, batchSize).Error
+}
+
+// Upsert 批量插入记录，唯一键冲突时更新 columns 指定的列，未指定时更新除主键和创建时间外的所有列
+// 乐观锁模型在冲突更新时版本号加一
+func (r *Repository[T]) Upsert(ctx context.Context, entities []*T, columns ...string) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	for _, entity := range entities {
+		r.initVersion(ctx, entity)
+	}
+
+	db := r.DB(ctx)
+	onConflict := clause.OnConflict{}
+	if len(columns) > 0 {
+		onConflict.DoUpdates = clause.AssignmentColumns(columns)
+	} else {
+		onConflict.UpdateAll = true
+	}
+	if r.versioned {
+		if onConflict.UpdateAll {
+			onConflict.UpdateAll = false
+			onConflict.DoUpdates = clause.AssignmentColumns(r.updatableColumns(db))
+		}
+		onConflict.DoUpdates = append(onConflict.DoUpdates, clause.Assignment{
+			Column: clause.Column{Name: r.version.DBName},
+			Value:  gorm.Expr("? + 1", clause.Column{Table: clause.CurrentTable, Name: r.version.DBName}),
+		})
+	}
+	return db.Clauses(onConflict).CreateInBatches(entities, defaultBatchSize).Error
+}
+
+// Get 按主键查询记录，记录不存在时返回 gorm.ErrRecordNotFound
+func (r *Repository[T]) Get(ctx context.Context, id uint, scopes ...Scope) (*T, error) {
+	entity := new(T)
+	if err := r.DB(ctx).Scopes(scopes...).First(entity, id).Error; err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// First 查询满足条件的第一条记录，记录不存在时返回 gorm.ErrRecordNotFound
+func (r *Repository[T]) First(ctx context.Context, scopes ...Scope) (*T, error) {
+	entity := new(T)
+	if err := r.DB(ctx).Scopes(scopes...).First(entity).Error; err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// Find 查询满足条件的所有记录
+func (r *Repository[T]) Find(ctx context.Context, scopes ...Scope) ([]T, error) {
+	var entities []T
+	if err := r.DB(ctx).Scopes(scopes...).Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Count 统计满足条件的记录数
+func (r *Repository[T]) Count(ctx context.Context, scopes ...Scope) (int64, error) {
+	var count int64
+	err := r.DB(ctx).Model(new(T)).Scopes(scopes...).Count(&count).Error
+	return count, err
+}
+
+// Exists 判断是否存在满足条件的记录
+func (r *Repository[T]) Exists(ctx context.Context, scopes ...Scope) (bool, error) {
+	var found []int
+	err := r.DB(ctx).Model(new(T)).Scopes(scopes...).Select("1").Limit(1).Find(&found).Error
+	return len(found) > 0, err
+}
+
+// Paginate 分页查询，page 由 common.ParsePage 解析
+func (r *Repository[T]) Paginate(ctx context.Context, page *common.Page, scopes ...Scope) (*common.PageResult[T], error) {
+	return common.Paginate[T](r.DB(ctx).Scopes(scopes...), page)
+}
+
+// Update 保存记录的所有字段（创建时间除外）
+// 乐观锁模型只在数据库中的版本号与 entity 一致时更新，并将版本号加一；
+// 版本不一致时返回 CodeConflict 业务错误，可通过 errors.Is(err, ErrStaleVersion) 判断
+func (r *Repository[T]) Update(ctx context.Context, entity *T) error {
+	db := r.DB(ctx)
+	if !r.versioned {
+		return db.Model(entity).Select("*").Omit("created_at").Updates(entity).Error
+	}
+
+	value := reflect.ValueOf(entity)
+	current, _ := r.version.ValueOf(ctx, value)
+	next := toUint64(current) + 1
+	if err := r.version.Set(ctx, value, next); err != nil {
+		return err
+	}
+
+	result := db.Model(entity).
+		Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: r.version.DBName}, Value: current}).
+		Select("*").Omit("created_at").
+		Updates(entity)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = common.WrapError(ErrStaleVersion, common.CodeConflict)
+	}
+	if result.Error != nil {
+		_ = r.version.Set(ctx, value, current)
+		return result.Error
+	}
+	return nil
+}
+
+// UpdateFields 按主键更新指定列，乐观锁模型同时将版本号加一，记录不存在时返回 gorm.ErrRecordNotFound
+func (r *Repository[T]) UpdateFields(ctx context.Context, id uint, fields map[string]interface{}) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	updates := make(map[string]interface{}, len(fields)+1)
+	for column, value := range fields {
+		updates[column] = value
+	}
+	if r.versioned {
+		updates[r.version.DBName] = gorm.Expr("? + 1", clause.Column{Table: clause.CurrentTable, Name: r.version.DBName})
+	}
+
+	result := r.DB(ctx).Model(new(T)).Where(r.primaryKey(id)).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return r.notFoundUnlessExists(r.DB(ctx), id)
+	}
+	return nil
+}
+
+// Delete 按主键删除记录，模型包含 DeletedAt 时为软删除
+func (r *Repository[T]) Delete(ctx context.Context, id uint) error {
+	return r.delete(r.DB(ctx), id)
+}
+
+// HardDelete 按主键物理删除记录（包括已软删除的记录）
+func (r *Repository[T]) HardDelete(ctx context.Context, id uint) error {
+	return r.delete(r.DB(ctx).Unscoped(), id)
+}
+
+// Restore 恢复已软删除的记录，记录未被删除时不返回错误，记录不存在时返回 gorm.ErrRecordNotFound
+func (r *Repository[T]) Restore(ctx context.Context, id uint) error {
+	result := r.DB(ctx).Unscoped().Model(new(T)).Where(r.primaryKey(id)).Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return r.notFoundUnlessExists(r.DB(ctx).Unscoped(), id)
+	}
+	return nil
+}
+
+// delete 删除记录，未删除任何记录时返回 gorm.ErrRecordNotFound
+func (r *Repository[T]) delete(db *gorm.DB, id uint) error {
+	result := db.Delete(new(T), id)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// notFoundUnlessExists 更新未修改任何行时确认记录是否存在，不存在时返回 gorm.ErrRecordNotFound
+// MySQL 默认返回实际修改的行数，写入与当前相同的值（或恢复未删除的记录）时 RowsAffected 也为 0
+func (r *Repository[T]) notFoundUnlessExists(db *gorm.DB, id uint) error {
+	var found []int
+	if err := db.Model(new(T)).Where(r.primaryKey(id)).Select("1").Limit(1).Find(&found).Error; err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// primaryKey 主键查询条件
+func (r *Repository[T]) primaryKey(id uint) clause.Expression {
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey}, Value: id}
+}
+
+// initVersion 为未设置版本号的新记录设置初始版本号
+func (r *Repository[T]) initVersion(ctx context.Context, entity *T) {
+	if !r.versioned {
+		return
+	}
+	value := reflect.ValueOf(entity)
+	if _, zero := r.version.ValueOf(ctx, value); zero {
+		_ = r.version.Set(ctx, value, 1)
+	}
+}
+
+// updatableColumns Upsert 冲突时更新的列：除主键、创建时间和版本号外的所有列
+func (r *Repository[T]) updatableColumns(db *gorm.DB) []string {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil
+	}
+	columns := make([]string, 0, len(stmt.Schema.DBNames))
+	for _, name := range stmt.Schema.DBNames {
+		field := stmt.Schema.LookUpField(name)
+		if field.PrimaryKey || field.AutoCreateTime > 0 || name == "created_at" || name == r.version.DBName {
+			continue
+		}
+		columns = append(columns, name)
+	}
+	return columns
+}
+
+// toUint64 将版本号转换为 uint64
+func toUint64(v interface{}) uint64 {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return uint64(rv.Int())
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return rv.Uint()
+	}
+	return 0
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txContextKey 事务在 context 中的键
+type txContextKey struct{}
+
+// TxManager 事务管理器，通过 context 传播事务，使同一调用链上的仓库共享同一个事务
+type TxManager struct {
+	db *gorm.DB
+}
+
+// NewTxManager 创建事务管理器
+func NewTxManager(db *gorm.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTx 在事务中执行 fn，fn 返回错误或 panic 时回滚，否则提交
+// ctx 中已有事务时不会开启新事务，而是创建保存点，fn 失败时只回滚到该保存点，外层事务可以继续
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	// 在已有事务上调用 Transaction 时，GORM 会使用 SAVEPOINT / ROLLBACK TO 实现嵌套事务
+	return DB(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		return fn(WithTx(ctx, tx))
+	})
+}
+
+// DB 返回当前上下文中的数据库会话，存在事务时返回事务
+func (m *TxManager) DB(ctx context.Context) *gorm.DB {
+	return DB(ctx, m.db)
+}
+
+// WithTx 返回携带事务的 context
+func WithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext 获取 context 中的事务
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
+// DB 返回绑定 ctx 的数据库会话：ctx 中存在事务时使用事务，否则使用 db
+// 手写的仓库应通过该函数访问数据库，以便参与 WithinTx 开启的事务
+func DB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,446 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/etcd"
+	"go-template/pkg/prometheus"
+	"go-template/utils/common"
+)
+
+// maxQueued 排队策略下最多积压的执行次数，超出时跳过
+const maxQueued = 16
+
+// OverlapPolicy 上一次执行尚未结束时的处理策略
+type OverlapPolicy string
+
+const (
+	OverlapSkip  OverlapPolicy = "skip"  // 跳过本次执行（默认）
+	OverlapQueue OverlapPolicy = "queue" // 等待上一次执行结束后再执行
+	OverlapAllow OverlapPolicy = "allow" // 允许并发执行
+)
+
+// parser cron 表达式解析器：标准 5 段、可选秒字段的 6 段，以及 @daily、@every 1h 等描述符
+var parser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Job 定时任务
+type Job struct {
+	Name      string                          // 任务名称，唯一
+	Schedule  string                          // cron 表达式，如 "0 3 * * *"、"*/30 * * * * *"、"@every 10m"
+	Timezone  string                          // 时区，如 Asia/Shanghai，为空时使用调度器默认时区
+	Jitter    time.Duration                   // 随机延迟上限，避免多个任务或服务同时触发
+	Overlap   OverlapPolicy                   // 重叠策略，默认 skip
+	Timeout   time.Duration                   // 单次执行超时时间，0 表示不限制
+	Singleton bool                            // 只在 leader 副本上执行，需要配置 SchedulerConfig.Election
+	Run       func(ctx context.Context) error // 任务逻辑，应遵守 ctx 的取消
+}
+
+// SchedulerConfig 调度器配置
+type SchedulerConfig struct {
+	Timezone    string              // 默认时区，默认 Local
+	HistorySize int                 // 每个任务保留的执行记录数，默认 20
+	Election    *etcd.Election      // leader 选举，由调度器负责运行，Singleton 任务必需
+	Metrics     *prometheus.Metrics // 执行结果和下次执行时间指标，可为 nil
+}
+
+// Run 一次执行记录
+type Run struct {
+	ScheduledAt time.Time  `json:"scheduled_at"`         // 计划执行时间
+	StartedAt   *time.Time `json:"started_at,omitempty"` // 实际开始时间，跳过的执行为空
+	DurationMs  int64      `json:"duration_ms"`          // 执行耗时（毫秒）
+	Result      string     `json:"result"`               // 执行结果
+	Error       string     `json:"error,omitempty"`      // 失败原因
+}
+
+// Status 任务状态
+type Status struct {
+	Name      string        `json:"name"`
+	Schedule  string        `json:"schedule"`
+	Timezone  string        `json:"timezone"`
+	Overlap   OverlapPolicy `json:"overlap"`
+	Singleton bool          `json:"singleton"`
+	NextRun   time.Time     `json:"next_run"` // 下次计划执行时间（不含随机延迟）
+	Running   int           `json:"running"`  // 正在执行的次数
+	Queued    int           `json:"queued"`   // 排队等待的次数
+	History   []Run         `json:"history"`  // 最近的执行记录，最新的在前
+}
+
+// entry 已注册的任务
+type entry struct {
+	job      Job
+	schedule cron.Schedule
+	location *time.Location
+
+	mu      sync.Mutex
+	next    time.Time
+	running int
+	queued  []time.Time
+	history []Run
+}
+
+// Scheduler 定时任务调度器，实现 app.Component
+// 每个任务按 cron 表达式在指定时区触发，支持随机延迟、重叠策略和超时；
+// Singleton 任务只在通过 etcd 选举出的 leader 副本上执行，失去领导权时正在执行的任务会被取消
+type Scheduler struct {
+	config *SchedulerConfig
+	logger *zhlog.Helper
+	tracer oteltrace.Tracer
+
+	mu      sync.RWMutex
+	entries map[string]*entry
+	started bool
+
+	loopCtx        context.Context
+	stopLoops      context.CancelFunc
+	jobCtx         context.Context
+	abortJobs      context.CancelFunc
+	stopElection   context.CancelFunc
+	loops, running sync.WaitGroup
+}
+
+// New 创建定时任务调度器
+func New(config *SchedulerConfig, logger *zhlog.Helper) *Scheduler {
+	if config.Timezone == "" {
+		config.Timezone = "Local"
+	}
+	if config.HistorySize <= 0 {
+		config.HistorySize = 20
+	}
+	return &Scheduler{
+		config:  config,
+		logger:  logger,
+		tracer:  otel.Tracer("go-template/pkg/scheduler"),
+		entries: make(map[string]*entry),
+	}
+}
+
+// Add 注册定时任务，调度器已启动时立即开始调度
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" {
+		return errors.New("scheduler: job name is empty")
+	}
+	if job.Run == nil {
+		return fmt.Errorf("scheduler: job %s has no Run function", job.Name)
+	}
+	switch job.Overlap {
+	case "":
+		job.Overlap = OverlapSkip
+	case OverlapSkip, OverlapQueue, OverlapAllow:
+	default:
+		return fmt.Errorf("scheduler: job %s has invalid overlap policy %q", job.Name, job.Overlap)
+	}
+	if job.Singleton && s.config.Election == nil {
+		return fmt.Errorf("scheduler: singleton job %s requires an election", job.Name)
+	}
+	if job.Timezone == "" {
+		job.Timezone = s.config.Timezone
+	}
+
+	location, err := time.LoadLocation(job.Timezone)
+	if err != nil {
+		return fmt.Errorf("scheduler: job %s: invalid timezone: %w", job.Name, err)
+	}
+	schedule, err := parser.Parse(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("scheduler: job %s: invalid schedule %q: %w", job.Name, job.Schedule, err)
+	}
+
+	e := &entry{job: job, schedule: schedule, location: location}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[job.Name]; ok {
+		return fmt.Errorf("scheduler: job %s already registered", job.Name)
+	}
+	s.entries[job.Name] = e
+	if s.started {
+		s.loops.Add(1)
+		go s.loop(e)
+	}
+	return nil
+}
+
+// MustAdd 注册定时任务，失败时 panic
+func (s *Scheduler) MustAdd(job Job) {
+	if err := s.Add(job); err != nil {
+		panic(err)
+	}
+}
+
+// Name 组件名称
+func (s *Scheduler) Name() string {
+	return "scheduler"
+}
+
+// Start 开始调度所有任务，配置了选举时同时参与 leader 选举
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.loopCtx, s.stopLoops = context.WithCancel(context.Background())
+	s.jobCtx, s.abortJobs = context.WithCancel(context.Background())
+
+	if s.config.Election != nil {
+		electionCtx, stopElection := context.WithCancel(context.Background())
+		s.stopElection = stopElection
+		go s.config.Election.Run(electionCtx)
+	}
+
+	for _, e := range s.entries {
+		s.loops.Add(1)
+		go s.loop(e)
+	}
+	s.started = true
+
+	s.logger.Info("定时任务调度器已启动", "jobs", len(s.entries))
+	return nil
+}
+
+// Stop 停止触发新的执行，等待正在执行的任务结束，ctx 到期后取消剩余任务，最后放弃领导权并等待选举退出
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.stopLoops == nil {
+		return nil
+	}
+	s.stopLoops()
+	s.loops.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("scheduler: running jobs interrupted: %w", ctx.Err())
+	}
+	s.abortJobs()
+
+	// 等待放弃领导权，避免 etcd 客户端先于选举关闭，导致领导权在租约到期前一直由已停止的副本持有
+	if s.stopElection != nil {
+		s.stopElection()
+		select {
+		case <-s.config.Election.Done():
+		case <-ctx.Done():
+			if err == nil {
+				err = fmt.Errorf("scheduler: resign leadership interrupted: %w", ctx.Err())
+			}
+		}
+	}
+	return err
+}
+
+// loop 按计划时间触发任务
+func (s *Scheduler) loop(e *entry) {
+	defer s.loops.Done()
+
+	for {
+		next := e.schedule.Next(time.Now().In(e.location))
+		if next.IsZero() {
+			s.logger.Warn("定时任务没有下次执行时间", "job", e.job.Name)
+			return
+		}
+		e.mu.Lock()
+		e.next = next
+		e.mu.Unlock()
+		if s.config.Metrics != nil {
+			s.config.Metrics.SetScheduleNextRun(e.job.Name, next)
+		}
+
+		wait := time.Until(next)
+		if e.job.Jitter > 0 {
+			wait += rand.N(e.job.Jitter)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-s.loopCtx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.fire(e, next)
+	}
+}
+
+// fire 按重叠策略触发一次执行
+func (s *Scheduler) fire(e *entry, scheduledAt time.Time) {
+	if e.job.Singleton && !s.config.Election.IsLeader() {
+		return
+	}
+
+	e.mu.Lock()
+	if e.running > 0 {
+		switch {
+		case e.job.Overlap == OverlapSkip, e.job.Overlap == OverlapQueue && len(e.queued) >= maxQueued:
+			e.mu.Unlock()
+			s.logger.Warn("上一次执行尚未结束，跳过本次执行", "job", e.job.Name, "scheduled_at", scheduledAt)
+			s.record(e, Run{ScheduledAt: scheduledAt, Result: prometheus.ScheduleSkipped})
+			return
+		case e.job.Overlap == OverlapQueue:
+			e.queued = append(e.queued, scheduledAt)
+			e.mu.Unlock()
+			return
+		}
+	}
+	e.running++
+	e.mu.Unlock()
+
+	s.running.Add(1)
+	go s.execute(e, scheduledAt)
+}
+
+// execute 执行任务，排队策略下依次执行积压的执行
+func (s *Scheduler) execute(e *entry, scheduledAt time.Time) {
+	defer s.running.Done()
+
+	for {
+		s.runOnce(e, scheduledAt)
+
+		e.mu.Lock()
+		if len(e.queued) > 0 && s.loopCtx.Err() == nil {
+			scheduledAt = e.queued[0]
+			e.queued = e.queued[1:]
+			e.mu.Unlock()
+			continue
+		}
+		e.queued = nil
+		e.running--
+		e.mu.Unlock()
+		return
+	}
+}
+
+// runOnce 执行一次任务并记录结果
+func (s *Scheduler) runOnce(e *entry, scheduledAt time.Time) {
+	ctx, cancel := context.WithCancel(s.jobCtx)
+	defer cancel()
+
+	// Singleton 任务在失去领导权时取消
+	if e.job.Singleton {
+		leaderCtx, ok := s.config.Election.LeaderContext()
+		if !ok {
+			return
+		}
+		stop := context.AfterFunc(leaderCtx, cancel)
+		defer stop()
+	}
+	if e.job.Timeout > 0 {
+		var timeoutCancel context.CancelFunc
+		ctx, timeoutCancel = context.WithTimeout(ctx, e.job.Timeout)
+		defer timeoutCancel()
+	}
+
+	ctx, span := s.tracer.Start(ctx, "scheduler "+e.job.Name,
+		oteltrace.WithAttributes(
+			attribute.String("scheduler.job", e.job.Name),
+			attribute.String("scheduler.scheduled_at", scheduledAt.Format(time.RFC3339)),
+		),
+	)
+	defer span.End()
+
+	start := time.Now().In(e.location)
+	err := s.call(ctx, e)
+	run := Run{
+		ScheduledAt: scheduledAt,
+		StartedAt:   &start,
+		DurationMs:  time.Since(start).Milliseconds(),
+		Result:      prometheus.ScheduleSucceeded,
+	}
+
+	if err != nil {
+		run.Result = prometheus.ScheduleFailed
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			run.Result = prometheus.ScheduleTimeout
+		}
+		run.Error = err.Error()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, run.Result)
+		s.logger.Error("定时任务执行失败", "job", e.job.Name, "result", run.Result, "duration", time.Since(start), "error", err)
+	}
+	s.record(e, run)
+}
+
+// call 调用任务函数，panic 转换为错误
+func (s *Scheduler) call(ctx context.Context, e *entry) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("scheduler: panic: %v", r)
+		}
+	}()
+	return e.job.Run(ctx)
+}
+
+// record 保存执行记录并更新指标
+func (s *Scheduler) record(e *entry, run Run) {
+	e.mu.Lock()
+	e.history = append([]Run{run}, e.history...)
+	if len(e.history) > s.config.HistorySize {
+		e.history = e.history[:s.config.HistorySize]
+	}
+	e.mu.Unlock()
+
+	if s.config.Metrics != nil {
+		s.config.Metrics.RecordScheduleRun(e.job.Name, run.Result, time.Duration(run.DurationMs)*time.Millisecond)
+	}
+}
+
+// Statuses 返回按名称排序的任务状态
+func (s *Scheduler) Statuses() []Status {
+	s.mu.RLock()
+	entries := make([]*entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, e)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].job.Name < entries[j].job.Name
+	})
+
+	statuses := make([]Status, 0, len(entries))
+	for _, e := range entries {
+		e.mu.Lock()
+		statuses = append(statuses, Status{
+			Name:      e.job.Name,
+			Schedule:  e.job.Schedule,
+			Timezone:  e.location.String(),
+			Overlap:   e.job.Overlap,
+			Singleton: e.job.Singleton,
+			NextRun:   e.next,
+			Running:   e.running,
+			Queued:    len(e.queued),
+			History:   append([]Run(nil), e.history...),
+		})
+		e.mu.Unlock()
+	}
+	return statuses
+}
+
+// Handler 管理接口：返回所有任务的下次执行时间和执行记录，以及当前副本是否为 leader
+func (s *Scheduler) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		leader := s.config.Election == nil || s.config.Election.IsLeader()
+		common.Success(c, gin.H{
+			"leader": leader,
+			"jobs":   s.Statuses(),
+		})
+	}
+}
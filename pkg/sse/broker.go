@@ -0,0 +1,431 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/prometheus"
+	"go-template/utils/common"
+)
+
+// LastEventIDHeader 客户端断线重连时携带的最后事件ID请求头
+const LastEventIDHeader = "Last-Event-ID"
+
+// BrokerConfig SSE 代理配置
+type BrokerConfig struct {
+	Redis           *redis.Client                                   // Redis 客户端，保存回放缓冲区并在实例间分发事件
+	Prefix          string                                          // 回放缓冲区键前缀，默认 sse:stream:
+	Channel         string                                          // 事件分发频道，默认 sse:events
+	ReplaySize      int64                                           // 每个流保留的事件数量（近似），默认 1000
+	ReplayTTL       time.Duration                                   // 流无新事件后回放缓冲区的保留时间，默认 1h
+	KeepAlive       time.Duration                                   // 保活注释的发送间隔，默认 15s
+	Retry           time.Duration                                   // 建议客户端的重连间隔，默认 3s
+	Buffer          int                                             // 每个连接的事件缓冲区大小，默认 64
+	Authenticate    func(c *gin.Context) (userID string, err error) // 可选，连接前认证；为空时读取 gin.Context 中的 user_id，没有时按客户端IP限制
+	MaxConnsPerUser int                                             // 每个用户在单个实例上的最大连接数，默认 5，负数表示不限制
+	MaxConns        int                                             // 单个实例的最大连接数，0 表示不限制
+	Metrics         *prometheus.Metrics                             // 可选，连接数和事件数指标
+}
+
+// setDefaults 填充默认值
+func (c *BrokerConfig) setDefaults() {
+	if c.Prefix == "" {
+		c.Prefix = "sse:stream:"
+	}
+	if c.Channel == "" {
+		c.Channel = "sse:events"
+	}
+	if c.ReplaySize <= 0 {
+		c.ReplaySize = 1000
+	}
+	if c.ReplayTTL <= 0 {
+		c.ReplayTTL = time.Hour
+	}
+	if c.KeepAlive <= 0 {
+		c.KeepAlive = 15 * time.Second
+	}
+	if c.Retry <= 0 {
+		c.Retry = 3 * time.Second
+	}
+	if c.Buffer <= 0 {
+		c.Buffer = 64
+	}
+	if c.MaxConnsPerUser == 0 {
+		c.MaxConnsPerUser = 5
+	}
+}
+
+// publishScript 追加事件到流的回放缓冲区并发布到分发频道，返回事件ID
+var publishScript = redis.NewScript(`
+local id = redis.call("XADD", KEYS[1], "MAXLEN", "~", ARGV[1], "*", "event", ARGV[2], "data", ARGV[3])
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+redis.call("PUBLISH", ARGV[5], cjson.encode({stream = ARGV[6], id = id, event = ARGV[2], data = ARGV[3]}))
+return id
+`)
+
+// envelope 分发频道中的事件
+type envelope struct {
+	Stream string `json:"stream"`
+	ID     string `json:"id"`
+	Event  string `json:"event"`
+	Data   string `json:"data"`
+}
+
+// subscriber 单个连接的事件缓冲区
+type subscriber struct {
+	stream   string
+	events   chan *Event
+	lagged   chan struct{} // 缓冲区已满时关闭，连接随之断开，客户端重连后从回放缓冲区补齐
+	lagOnce  sync.Once
+	isLagged atomic.Bool
+}
+
+// lag 标记连接消费过慢
+func (s *subscriber) lag() {
+	s.lagOnce.Do(func() {
+		s.isLagged.Store(true)
+		close(s.lagged)
+	})
+}
+
+// Broker SSE 代理，同时是应用组件
+// Publish 将事件写入 Redis Stream 作为回放缓冲区并通过 Pub/Sub 分发，各实例收到后推送给本地订阅了该流的连接；
+// 客户端携带 Last-Event-ID 重连时先从回放缓冲区补发错过的事件。
+// 连接的事件缓冲区已满时断开该连接而不阻塞其他连接，客户端按 retry 间隔重连后补齐
+type Broker struct {
+	config *BrokerConfig
+	logger *zhlog.Helper
+
+	mu      sync.RWMutex
+	streams map[string]map[*subscriber]struct{}
+	conns   map[string]int // 用户 -> 连接数
+	total   int
+
+	closing chan struct{}
+	stop    context.CancelFunc
+	done    chan struct{}
+}
+
+// NewBroker 创建 SSE 代理
+func NewBroker(config *BrokerConfig, logger *zhlog.Helper) *Broker {
+	config.setDefaults()
+	return &Broker{
+		config:  config,
+		logger:  logger,
+		streams: make(map[string]map[*subscriber]struct{}),
+		conns:   make(map[string]int),
+		closing: make(chan struct{}),
+	}
+}
+
+// Publish 发布事件到流，返回分配的事件ID
+func (b *Broker) Publish(ctx context.Context, stream string, event *Event) (string, error) {
+	id, err := publishScript.Run(ctx, b.config.Redis,
+		[]string{b.config.Prefix + stream},
+		b.config.ReplaySize, event.Event, event.Data, b.config.ReplayTTL.Milliseconds(), b.config.Channel, stream,
+	).Text()
+	if err != nil {
+		return "", fmt.Errorf("sse: publish %s: %w", stream, err)
+	}
+	return id, nil
+}
+
+// Handler 返回推送指定流的 Gin 处理器
+func (b *Broker) Handler(stream string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		b.Serve(c, stream)
+	}
+}
+
+// Serve 向客户端推送流中的事件，直到客户端断开、消费过慢或服务停止
+// 认证失败返回认证函数给出的业务状态码（默认 CodeUnauthorized），超出连接数限制返回 CodeWSConnectionLimit
+func (b *Broker) Serve(c *gin.Context, stream string) {
+	userID, err := b.authenticate(c)
+	if err != nil {
+		b.recordConnection(prometheus.WSRejectedAuth)
+		code := common.CodeUnauthorized
+		var e *common.Error
+		if errors.As(err, &e) {
+			code = e.Code
+		}
+		common.BusinessResponse(c, code, nil)
+		c.Abort()
+		return
+	}
+
+	sub, ok := b.subscribe(userID, stream)
+	if !ok {
+		b.recordConnection(prometheus.WSRejectedLimit)
+		common.BusinessResponse(c, common.CodeWSConnectionLimit, nil)
+		c.Abort()
+		return
+	}
+	b.recordConnection(prometheus.WSAccepted)
+	defer func() {
+		b.unsubscribe(userID, sub)
+		if b.config.Metrics != nil {
+			b.config.Metrics.RecordSSEDisconnection(sub.isLagged.Load())
+		}
+	}()
+
+	h := c.Writer.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // 关闭 Nginx 代理缓冲
+	c.Status(http.StatusOK)
+	if _, err := fmt.Fprintf(c.Writer, "retry: %d\n\n", b.config.Retry.Milliseconds()); err != nil {
+		return
+	}
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	lastID := c.GetHeader(LastEventIDHeader)
+	if lastID == "" {
+		// EventSource 无法自定义请求头，首次连接时可通过查询参数指定
+		lastID = c.Query("last_event_id")
+	}
+	if !validID(lastID) {
+		lastID = ""
+	}
+	if lastID != "" {
+		lastID, err = b.replay(ctx, c.Writer, stream, lastID)
+		if err != nil {
+			b.logger.Warn("SSE 回放失败", "stream", stream, "last_event_id", lastID, "error", err)
+			return
+		}
+	}
+
+	ticker := time.NewTicker(b.config.KeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case event := <-sub.events:
+			// 跳过回放时已发送的事件
+			if lastID != "" && !idAfter(event.ID, lastID) {
+				continue
+			}
+			if err := event.writeTo(c.Writer); err != nil {
+				return
+			}
+			c.Writer.Flush()
+			lastID = event.ID
+			b.recordEvent(stream, false)
+		case <-ticker.C:
+			if _, err := c.Writer.WriteString(": keepalive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-sub.lagged:
+			b.logger.Warn("SSE 客户端消费过慢，断开连接", "stream", stream, "user_id", userID)
+			return
+		case <-ctx.Done():
+			return
+		case <-b.closing:
+			return
+		}
+	}
+}
+
+// replay 补发回放缓冲区中晚于 lastID 的事件，返回最后发送的事件ID
+func (b *Broker) replay(ctx context.Context, w gin.ResponseWriter, stream, lastID string) (string, error) {
+	for {
+		msgs, err := b.config.Redis.XRangeN(ctx, b.config.Prefix+stream, lastID, "+", 100).Result()
+		if err != nil {
+			return lastID, err
+		}
+		sent := false
+		for _, msg := range msgs {
+			if !idAfter(msg.ID, lastID) {
+				continue
+			}
+			event := &Event{ID: msg.ID}
+			event.Event, _ = msg.Values["event"].(string)
+			event.Data, _ = msg.Values["data"].(string)
+			if err := event.writeTo(w); err != nil {
+				return lastID, err
+			}
+			lastID = msg.ID
+			sent = true
+			b.recordEvent(stream, true)
+		}
+		w.Flush()
+		if !sent {
+			return lastID, nil
+		}
+	}
+}
+
+// Connections 返回本实例的连接数
+func (b *Broker) Connections() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.total
+}
+
+// Name 组件名称
+func (b *Broker) Name() string {
+	return "sse"
+}
+
+// Start 订阅分发频道
+func (b *Broker) Start(ctx context.Context) error {
+	subCtx, stop := context.WithCancel(context.Background())
+	pubsub := b.config.Redis.Subscribe(subCtx, b.config.Channel)
+	// 等待订阅确认，确保启动完成后发布的事件不会丢失
+	if _, err := pubsub.Receive(ctx); err != nil {
+		stop()
+		_ = pubsub.Close()
+		return fmt.Errorf("sse: subscribe %s: %w", b.config.Channel, err)
+	}
+
+	b.stop = stop
+	b.done = make(chan struct{})
+	go b.dispatch(subCtx, pubsub)
+
+	b.logger.Info("SSE 事件分发已启动", "channel", b.config.Channel)
+	return nil
+}
+
+// Stop 停止订阅并结束全部推送连接
+func (b *Broker) Stop(ctx context.Context) error {
+	b.mu.Lock()
+	select {
+	case <-b.closing:
+	default:
+		close(b.closing)
+	}
+	b.mu.Unlock()
+
+	if b.stop == nil {
+		return nil
+	}
+	b.stop()
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dispatch 接收分发频道中的事件并放入本地订阅者的缓冲区，go-redis 在连接断开后自动重新订阅
+func (b *Broker) dispatch(ctx context.Context, pubsub *redis.PubSub) {
+	defer close(b.done)
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case m, ok := <-ch:
+			if !ok {
+				return
+			}
+			env := &envelope{}
+			if err := json.Unmarshal([]byte(m.Payload), env); err != nil {
+				b.logger.Warn("忽略无法解析的 SSE 事件", "channel", m.Channel, "error", err)
+				continue
+			}
+			b.deliver(env)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deliver 将事件放入订阅了该流的本地连接的缓冲区，缓冲区已满的连接被标记为消费过慢
+func (b *Broker) deliver(env *envelope) {
+	event := &Event{ID: env.ID, Event: env.Event, Data: env.Data}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.streams[env.Stream] {
+		select {
+		case sub.events <- event:
+		default:
+			sub.lag()
+		}
+	}
+}
+
+// subscribe 检查连接数限制并注册订阅者
+func (b *Broker) subscribe(userID, stream string) (*subscriber, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.config.MaxConns > 0 && b.total >= b.config.MaxConns {
+		return nil, false
+	}
+	if b.config.MaxConnsPerUser > 0 && b.conns[userID] >= b.config.MaxConnsPerUser {
+		return nil, false
+	}
+	sub := &subscriber{
+		stream: stream,
+		events: make(chan *Event, b.config.Buffer),
+		lagged: make(chan struct{}),
+	}
+	if b.streams[stream] == nil {
+		b.streams[stream] = make(map[*subscriber]struct{})
+	}
+	b.streams[stream][sub] = struct{}{}
+	b.conns[userID]++
+	b.total++
+	return sub, true
+}
+
+// unsubscribe 移除订阅者
+func (b *Broker) unsubscribe(userID string, sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.streams[sub.stream], sub)
+	if len(b.streams[sub.stream]) == 0 {
+		delete(b.streams, sub.stream)
+	}
+	if b.conns[userID]--; b.conns[userID] <= 0 {
+		delete(b.conns, userID)
+	}
+	b.total--
+}
+
+// authenticate 认证连接，返回用于连接数限制的用户标识
+func (b *Broker) authenticate(c *gin.Context) (string, error) {
+	if b.config.Authenticate != nil {
+		userID, err := b.config.Authenticate(c)
+		if err == nil && userID == "" {
+			err = common.NewError(common.CodeUnauthorized)
+		}
+		return userID, err
+	}
+	if v, ok := c.Get("user_id"); ok && v != nil {
+		if userID := fmt.Sprint(v); userID != "" {
+			return userID, nil
+		}
+	}
+	return "ip:" + c.ClientIP(), nil
+}
+
+// recordConnection 记录连接请求结果
+func (b *Broker) recordConnection(result string) {
+	if b.config.Metrics != nil {
+		b.config.Metrics.RecordSSEConnection(result)
+	}
+}
+
+// recordEvent 记录写出的事件
+func (b *Broker) recordEvent(stream string, replayed bool) {
+	if b.config.Metrics != nil {
+		b.config.Metrics.RecordSSEEvent(stream, replayed)
+	}
+}
@@ -0,0 +1,76 @@
+package sse
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Event 服务端推送事件
+type Event struct {
+	ID    string `json:"id,omitempty"`    // 事件ID，由 Publish 分配，客户端断线重连时通过 Last-Event-ID 返回
+	Event string `json:"event,omitempty"` // 事件名称，为空时客户端按 message 事件处理
+	Data  string `json:"data"`            // 事件数据，可包含换行
+}
+
+// NewEvent 创建事件，data 为 string 或 []byte 时原样发送，其他类型编码为 JSON
+func NewEvent(name string, data interface{}) (*Event, error) {
+	switch v := data.(type) {
+	case string:
+		return &Event{Event: name, Data: v}, nil
+	case []byte:
+		return &Event{Event: name, Data: string(v)}, nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{Event: name, Data: string(raw)}, nil
+}
+
+// writeTo 按 text/event-stream 格式写出事件
+func (e *Event) writeTo(w io.Writer) error {
+	var b strings.Builder
+	if e.ID != "" {
+		b.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + e.Event + "\n")
+	}
+	for _, line := range strings.Split(e.Data, "\n") {
+		b.WriteString("data: " + strings.TrimSuffix(line, "\r") + "\n")
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// idAfter 判断 Redis Stream 形式的事件ID（<毫秒>-<序号>）a 是否晚于 b，无法解析的ID视为最早
+func idAfter(a, b string) bool {
+	am, as := parseID(a)
+	bm, bs := parseID(b)
+	if am != bm {
+		return am > bm
+	}
+	return as > bs
+}
+
+// parseID 解析事件ID
+func parseID(id string) (uint64, uint64) {
+	ms, seq, _ := strings.Cut(id, "-")
+	m, _ := strconv.ParseUint(ms, 10, 64)
+	s, _ := strconv.ParseUint(seq, 10, 64)
+	return m, s
+}
+
+// validID 判断是否为 Publish 分配的事件ID
+func validID(id string) bool {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return false
+	}
+	_, err1 := strconv.ParseUint(ms, 10, 64)
+	_, err2 := strconv.ParseUint(seq, 10, 64)
+	return err1 == nil && err2 == nil
+}
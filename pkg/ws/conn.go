@@ -0,0 +1,213 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"go-template/pkg/prometheus"
+	"go-template/utils/common"
+)
+
+// 连接关闭原因，用于日志和指标
+const (
+	CloseClient          = "client_closed"     // 客户端关闭
+	CloseHeartbeat       = "heartbeat_timeout" // 心跳超时
+	CloseSlowConsumer    = "slow_consumer"     // 发送缓冲区已满
+	CloseReadError       = "read_error"        // 读取失败
+	CloseWriteError      = "write_error"       // 写入失败
+	CloseServer          = "server_closed"     // 服务端主动关闭
+	CloseServerShutdown  = "server_shutdown"   // 服务停止
+	CloseMessageTooLarge = "message_too_large" // 消息超出大小限制
+)
+
+var (
+	// ErrClosed 连接已关闭
+	ErrClosed = errors.New("ws: connection closed")
+	// ErrSendBufferFull 发送缓冲区已满，连接已被关闭
+	ErrSendBufferFull = errors.New("ws: send buffer full")
+)
+
+// Conn 单个 WebSocket 连接
+// 每个连接有独立的读协程和写协程，所有写操作通过发送缓冲区交给写协程，Send 可并发调用
+type Conn struct {
+	id     string
+	userID string
+	locale string
+	hub    *Hub
+	ws     *websocket.Conn
+	send   chan []byte
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	closeOnce sync.Once
+	reason    string
+}
+
+// ID 连接ID
+func (c *Conn) ID() string {
+	return c.id
+}
+
+// UserID 连接所属的用户ID
+func (c *Conn) UserID() string {
+	return c.userID
+}
+
+// Context 连接的上下文，连接关闭时取消
+func (c *Conn) Context() context.Context {
+	return c.ctx
+}
+
+// Send 发送消息，发送缓冲区已满时关闭连接并返回 ErrSendBufferFull
+func (c *Conn) Send(msg *Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if err := c.enqueue(data); err != nil {
+		return err
+	}
+	if c.hub.config.Metrics != nil {
+		c.hub.config.Metrics.RecordWSMessage(prometheus.WSOutbound, msg.Type)
+	}
+	return nil
+}
+
+// SendError 发送错误回复，id 为对应的客户端消息ID
+func (c *Conn) SendError(id string, err error) error {
+	e := common.FromError(err)
+	return c.Send(errorMessage(id, e, c.locale))
+}
+
+// Close 关闭连接
+func (c *Conn) Close() {
+	c.close(CloseServer)
+}
+
+// enqueue 将编码后的消息放入发送缓冲区
+func (c *Conn) enqueue(data []byte) error {
+	select {
+	case <-c.ctx.Done():
+		return ErrClosed
+	default:
+	}
+
+	select {
+	case c.send <- data:
+		return nil
+	case <-c.ctx.Done():
+		return ErrClosed
+	default:
+		if c.hub.config.Metrics != nil {
+			c.hub.config.Metrics.RecordWSDropped()
+		}
+		c.close(CloseSlowConsumer)
+		return ErrSendBufferFull
+	}
+}
+
+// close 记录关闭原因并通知读写协程退出，只有第一次调用生效
+func (c *Conn) close(reason string) {
+	c.closeOnce.Do(func() {
+		c.reason = reason
+		c.cancel()
+	})
+}
+
+// readPump 读取客户端消息并分发，收到 pong 或任意消息时延长读取截止时间
+func (c *Conn) readPump() {
+	config := c.hub.config
+	c.ws.SetReadLimit(config.MaxMessageSize)
+	_ = c.ws.SetReadDeadline(time.Now().Add(config.PongTimeout))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(config.PongTimeout))
+	})
+
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			c.close(readCloseReason(err))
+			return
+		}
+		_ = c.ws.SetReadDeadline(time.Now().Add(config.PongTimeout))
+
+		msg := &Message{}
+		if err := json.Unmarshal(data, msg); err != nil || msg.Type == "" {
+			if config.Metrics != nil {
+				config.Metrics.RecordWSMessage(prometheus.WSInbound, prometheus.WSUnknownType)
+			}
+			_ = c.SendError(msg.ID, common.NewError(common.CodeWSMessageInvalid))
+			continue
+		}
+		c.hub.dispatch(c, msg)
+	}
+}
+
+// writePump 写出发送缓冲区中的消息并定期发送 ping，连接关闭时写出关闭帧
+func (c *Conn) writePump() {
+	config := c.hub.config
+	ticker := time.NewTicker(config.PingInterval)
+	defer func() {
+		ticker.Stop()
+		_ = c.ws.Close()
+	}()
+
+	for {
+		select {
+		case data := <-c.send:
+			_ = c.ws.SetWriteDeadline(time.Now().Add(config.WriteTimeout))
+			if err := c.ws.WriteMessage(websocket.TextMessage, data); err != nil {
+				c.close(CloseWriteError)
+				return
+			}
+		case <-ticker.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(config.WriteTimeout)); err != nil {
+				c.close(CloseWriteError)
+				return
+			}
+		case <-c.ctx.Done():
+			code, text := closeFrame(c.reason, c.locale)
+			_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text),
+				time.Now().Add(config.WriteTimeout))
+			return
+		}
+	}
+}
+
+// readCloseReason 根据读取错误判断关闭原因
+func readCloseReason(err error) string {
+	var netErr net.Error
+	switch {
+	case websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived):
+		return CloseClient
+	case errors.Is(err, websocket.ErrReadLimit):
+		return CloseMessageTooLarge
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return CloseHeartbeat
+	default:
+		return CloseReadError
+	}
+}
+
+// closeFrame 返回关闭原因对应的关闭码和说明，说明为业务状态码对应的本地化消息
+func closeFrame(reason, locale string) (int, string) {
+	switch reason {
+	case CloseHeartbeat:
+		return websocket.CloseGoingAway, common.GetLocaleMessage(locale, common.CodeWSHeartbeatTimeout, nil)
+	case CloseSlowConsumer:
+		return websocket.ClosePolicyViolation, common.GetLocaleMessage(locale, common.CodeWSDisconnected, nil)
+	case CloseMessageTooLarge:
+		return websocket.CloseMessageTooBig, common.GetLocaleMessage(locale, common.CodeMessageTooLarge, nil)
+	case CloseServerShutdown:
+		return websocket.CloseGoingAway, ""
+	default:
+		return websocket.CloseNormalClosure, ""
+	}
+}
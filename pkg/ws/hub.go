@@ -0,0 +1,505 @@
+package ws
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+
+	"go-template/pkg/prometheus"
+	"go-template/utils/common"
+)
+
+// HandlerFunc 消息处理器，返回错误时向客户端发送 error 类型的回复
+type HandlerFunc func(conn *Conn, msg *Message) error
+
+// HubConfig WebSocket 中心配置
+type HubConfig struct {
+	Redis           *redis.Client                                   // 可选，跨实例投递消息；为空时只投递到本实例的连接
+	Channel         string                                          // Redis 频道，默认 ws:broadcast
+	Authenticate    func(c *gin.Context) (userID string, err error) // 升级前认证，为空时读取认证中间件写入 gin.Context 的 user_id
+	MaxConnsPerUser int                                             // 每个用户在单个实例上的最大连接数，默认 5，负数表示不限制
+	MaxConns        int                                             // 单个实例的最大连接数，0 表示不限制
+	AllowedOrigins  []string                                        // 允许的 Origin，为空时只允许同源，* 表示全部允许
+	PingInterval    time.Duration                                   // ping 间隔，默认 30s
+	PongTimeout     time.Duration                                   // 超过该时间未收到 pong 或消息时断开，默认 60s
+	WriteTimeout    time.Duration                                   // 单次写入超时，默认 10s
+	MaxMessageSize  int64                                           // 客户端消息最大字节数，默认 64KB
+	SendBuffer      int                                             // 每个连接的发送缓冲区大小，默认 256
+	OnConnect       func(conn *Conn)                                // 可选，连接建立后调用
+	OnDisconnect    func(conn *Conn, reason string)                 // 可选，连接关闭后调用
+	Metrics         *prometheus.Metrics                             // 可选，连接数和消息数指标
+}
+
+// setDefaults 填充默认值
+func (c *HubConfig) setDefaults() {
+	if c.Channel == "" {
+		c.Channel = "ws:broadcast"
+	}
+	if c.MaxConnsPerUser == 0 {
+		c.MaxConnsPerUser = 5
+	}
+	if c.PingInterval <= 0 {
+		c.PingInterval = 30 * time.Second
+	}
+	if c.PongTimeout <= 0 {
+		c.PongTimeout = 60 * time.Second
+	}
+	if c.WriteTimeout <= 0 {
+		c.WriteTimeout = 10 * time.Second
+	}
+	if c.MaxMessageSize <= 0 {
+		c.MaxMessageSize = 64 << 10
+	}
+	if c.SendBuffer <= 0 {
+		c.SendBuffer = 256
+	}
+}
+
+// envelope Redis 频道中的投递消息，UserID 为空表示广播给全部连接
+type envelope struct {
+	UserID  string   `json:"user_id,omitempty"`
+	Message *Message `json:"message"`
+}
+
+// Hub WebSocket 中心，管理本实例的连接并按用户投递消息，同时是应用组件
+// 配置 Redis 时 SendToUser 和 Broadcast 发布到 Redis 频道，由各实例（包括本实例）收到后投递给本地连接
+type Hub struct {
+	config   *HubConfig
+	logger   *zhlog.Helper
+	upgrader websocket.Upgrader
+
+	mu       sync.RWMutex
+	users    map[string]map[*Conn]struct{}
+	pending  map[string]int // 已通过限制检查、正在升级的连接数
+	total    int
+	closed   bool
+	handlers map[string]HandlerFunc
+
+	conns sync.WaitGroup
+	stop  context.CancelFunc
+	done  chan struct{}
+}
+
+// NewHub 创建 WebSocket 中心
+func NewHub(config *HubConfig, logger *zhlog.Helper) *Hub {
+	config.setDefaults()
+	h := &Hub{
+		config:   config,
+		logger:   logger,
+		users:    make(map[string]map[*Conn]struct{}),
+		pending:  make(map[string]int),
+		handlers: make(map[string]HandlerFunc),
+	}
+	h.upgrader = websocket.Upgrader{
+		HandshakeTimeout: 10 * time.Second,
+		CheckOrigin:      h.checkOrigin,
+	}
+	return h
+}
+
+// Handle 注册消息处理器，同一类型重复注册时覆盖
+func (h *Hub) Handle(msgType string, fn HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handlers[msgType] = fn
+}
+
+// ServeWS 认证并升级连接的 Gin 处理器
+// 认证失败返回认证函数给出的业务状态码（默认 CodeUnauthorized），超出连接数限制返回 CodeWSConnectionLimit
+func (h *Hub) ServeWS(c *gin.Context) {
+	userID, err := h.authenticate(c)
+	if err != nil {
+		h.recordConnection(prometheus.WSRejectedAuth)
+		code := common.CodeUnauthorized
+		var e *common.Error
+		if errors.As(err, &e) {
+			code = e.Code
+		}
+		common.WSResponse(c, code, nil)
+		c.Abort()
+		return
+	}
+
+	if !h.reserve(userID) {
+		h.recordConnection(prometheus.WSRejectedLimit)
+		common.WSResponse(c, common.CodeWSConnectionLimit, nil)
+		c.Abort()
+		return
+	}
+
+	wsConn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade 失败时已写出 HTTP 错误响应
+		h.release(userID)
+		h.recordConnection(prometheus.WSUpgradeFailed)
+		h.logger.Warn("WebSocket 升级失败", "user_id", userID, "error", err)
+		c.Abort()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	conn := &Conn{
+		id:     newConnID(),
+		userID: userID,
+		locale: common.Locale(c),
+		hub:    h,
+		ws:     wsConn,
+		send:   make(chan []byte, h.config.SendBuffer),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	if !h.add(conn) {
+		// 服务已停止，放弃连接
+		conn.close(CloseServerShutdown)
+		h.release(userID)
+		conn.writePump()
+		return
+	}
+	h.recordConnection(prometheus.WSAccepted)
+	h.logger.Debug("WebSocket 已连接", "conn_id", conn.id, "user_id", userID)
+
+	go h.run(conn)
+}
+
+// run 运行连接的读写协程，连接关闭后清理
+func (h *Hub) run(conn *Conn) {
+	defer h.conns.Done()
+
+	if h.config.OnConnect != nil {
+		h.config.OnConnect(conn)
+	}
+
+	writeDone := make(chan struct{})
+	go func() {
+		defer close(writeDone)
+		conn.writePump()
+	}()
+	conn.readPump()
+	<-writeDone
+
+	h.remove(conn)
+	if h.config.Metrics != nil {
+		h.config.Metrics.RecordWSDisconnection(conn.reason)
+	}
+	h.logger.Debug("WebSocket 已断开", "conn_id", conn.id, "user_id", conn.userID, "reason", conn.reason)
+	if h.config.OnDisconnect != nil {
+		h.config.OnDisconnect(conn, conn.reason)
+	}
+}
+
+// dispatch 将客户端消息交给对应类型的处理器
+func (h *Hub) dispatch(conn *Conn, msg *Message) {
+	h.mu.RLock()
+	fn, ok := h.handlers[msg.Type]
+	h.mu.RUnlock()
+
+	// 只有已注册的消息类型作为指标标签
+	if h.config.Metrics != nil {
+		msgType := msg.Type
+		if !ok {
+			msgType = prometheus.WSUnknownType
+		}
+		h.config.Metrics.RecordWSMessage(prometheus.WSInbound, msgType)
+	}
+	if !ok {
+		_ = conn.SendError(msg.ID, common.NewError(common.CodeWSMessageInvalid))
+		return
+	}
+	if err := fn(conn, msg); err != nil {
+		_ = conn.SendError(msg.ID, err)
+	}
+}
+
+// SendToUser 向用户的全部连接发送消息，配置 Redis 时投递到所有实例
+func (h *Hub) SendToUser(ctx context.Context, userID string, msg *Message) error {
+	return h.publish(ctx, &envelope{UserID: userID, Message: msg})
+}
+
+// Broadcast 向全部连接发送消息，配置 Redis 时投递到所有实例
+func (h *Hub) Broadcast(ctx context.Context, msg *Message) error {
+	return h.publish(ctx, &envelope{Message: msg})
+}
+
+// Connections 返回本实例的连接数
+func (h *Hub) Connections() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.total
+}
+
+// UserConnections 返回用户在本实例的连接
+func (h *Hub) UserConnections(userID string) []*Conn {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	conns := make([]*Conn, 0, len(h.users[userID]))
+	for conn := range h.users[userID] {
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+// Name 组件名称
+func (h *Hub) Name() string {
+	return "websocket"
+}
+
+// Start 配置 Redis 时订阅投递频道
+func (h *Hub) Start(ctx context.Context) error {
+	if h.config.Redis == nil {
+		return nil
+	}
+
+	subCtx, stop := context.WithCancel(context.Background())
+	sub := h.config.Redis.Subscribe(subCtx, h.config.Channel)
+	// 等待订阅确认，确保启动完成后发布的消息不会丢失
+	if _, err := sub.Receive(ctx); err != nil {
+		stop()
+		_ = sub.Close()
+		return fmt.Errorf("ws: subscribe %s: %w", h.config.Channel, err)
+	}
+
+	h.stop = stop
+	h.done = make(chan struct{})
+	go h.subscribe(subCtx, sub)
+
+	h.logger.Info("WebSocket 跨实例投递已启动", "channel", h.config.Channel)
+	return nil
+}
+
+// Stop 停止订阅，关闭全部连接并等待连接清理完成
+func (h *Hub) Stop(ctx context.Context) error {
+	if h.stop != nil {
+		h.stop()
+		select {
+		case <-h.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	h.mu.Lock()
+	h.closed = true
+	var conns []*Conn
+	for _, userConns := range h.users {
+		for conn := range userConns {
+			conns = append(conns, conn)
+		}
+	}
+	h.mu.Unlock()
+	for _, conn := range conns {
+		conn.close(CloseServerShutdown)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.conns.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// subscribe 接收 Redis 频道中的消息并投递给本地连接，go-redis 在连接断开后自动重新订阅
+func (h *Hub) subscribe(ctx context.Context, sub *redis.PubSub) {
+	defer close(h.done)
+	defer sub.Close()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case m, ok := <-ch:
+			if !ok {
+				return
+			}
+			env := &envelope{}
+			if err := json.Unmarshal([]byte(m.Payload), env); err != nil || env.Message == nil {
+				h.logger.Warn("忽略无法解析的 WebSocket 投递消息", "channel", m.Channel, "error", err)
+				continue
+			}
+			h.deliver(env)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// publish 发布投递消息，未配置 Redis 时直接投递给本地连接
+func (h *Hub) publish(ctx context.Context, env *envelope) error {
+	if env.Message.Time == 0 {
+		env.Message.Time = time.Now().UnixMilli()
+	}
+	if h.config.Redis == nil {
+		h.deliver(env)
+		return nil
+	}
+	data, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	return h.config.Redis.Publish(ctx, h.config.Channel, data).Err()
+}
+
+// deliver 投递给本地连接，消息只编码一次
+func (h *Hub) deliver(env *envelope) {
+	data, err := json.Marshal(env.Message)
+	if err != nil {
+		h.logger.Warn("WebSocket 消息编码失败", "type", env.Message.Type, "error", err)
+		return
+	}
+
+	h.mu.RLock()
+	var conns []*Conn
+	if env.UserID != "" {
+		for conn := range h.users[env.UserID] {
+			conns = append(conns, conn)
+		}
+	} else {
+		for _, userConns := range h.users {
+			for conn := range userConns {
+				conns = append(conns, conn)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, conn := range conns {
+		if conn.enqueue(data) == nil && h.config.Metrics != nil {
+			h.config.Metrics.RecordWSMessage(prometheus.WSOutbound, env.Message.Type)
+		}
+	}
+}
+
+// authenticate 认证升级请求，返回用户ID
+func (h *Hub) authenticate(c *gin.Context) (string, error) {
+	if h.config.Authenticate != nil {
+		userID, err := h.config.Authenticate(c)
+		if err == nil && userID == "" {
+			err = common.NewError(common.CodeWSUserRequired)
+		}
+		return userID, err
+	}
+	if v, ok := c.Get("user_id"); ok && v != nil {
+		if userID := fmt.Sprint(v); userID != "" {
+			return userID, nil
+		}
+	}
+	return "", common.NewError(common.CodeWSUserRequired)
+}
+
+// reserve 检查连接数限制并预占一个名额
+func (h *Hub) reserve(userID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.config.MaxConns > 0 && h.total >= h.config.MaxConns {
+		return false
+	}
+	if h.config.MaxConnsPerUser > 0 && len(h.users[userID])+h.pending[userID] >= h.config.MaxConnsPerUser {
+		return false
+	}
+	h.total++
+	h.pending[userID]++
+	return true
+}
+
+// release 释放预占的名额
+func (h *Hub) release(userID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.total--
+	h.unreserve(userID)
+}
+
+// add 将预占的名额转为连接并计入 conns，服务已停止时返回 false
+func (h *Hub) add(conn *Conn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.unreserve(conn.userID)
+	if h.users[conn.userID] == nil {
+		h.users[conn.userID] = make(map[*Conn]struct{})
+	}
+	h.users[conn.userID][conn] = struct{}{}
+	// 与 closed 检查在同一把锁内登记，保证 Stop 等待时已计入该连接
+	h.conns.Add(1)
+	return true
+}
+
+// remove 移除已关闭的连接
+func (h *Hub) remove(conn *Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.total--
+	h.unlink(conn.userID, conn)
+}
+
+// unreserve 减少用户的预占名额，调用方需持有 mu
+func (h *Hub) unreserve(userID string) {
+	if h.pending[userID]--; h.pending[userID] <= 0 {
+		delete(h.pending, userID)
+	}
+}
+
+// unlink 从用户的连接集合中删除，调用方需持有 mu
+func (h *Hub) unlink(userID string, conn *Conn) {
+	delete(h.users[userID], conn)
+	if len(h.users[userID]) == 0 {
+		delete(h.users, userID)
+	}
+}
+
+// checkOrigin 校验 Origin 请求头
+func (h *Hub) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || len(h.config.AllowedOrigins) == 0 {
+		return origin == "" || sameOrigin(r, origin)
+	}
+	for _, allowed := range h.config.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// recordConnection 记录连接请求结果
+func (h *Hub) recordConnection(result string) {
+	if h.config.Metrics != nil {
+		h.config.Metrics.RecordWSConnection(result)
+	}
+}
+
+// sameOrigin Origin 的主机与请求的 Host 相同
+func sameOrigin(r *http.Request, origin string) bool {
+	for _, scheme := range []string{"http://", "https://"} {
+		if origin == scheme+r.Host {
+			return true
+		}
+	}
+	return false
+}
+
+// newConnID 生成32位十六进制随机连接ID
+func newConnID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,58 @@
+package ws
+
+import (
+	"encoding/json"
+	"time"
+
+	"go-template/utils/common"
+)
+
+// TypeError 错误消息类型，服务端处理客户端消息失败时发送
+const TypeError = "error"
+
+// Message WebSocket 消息信封，客户端和服务端使用相同的 JSON 结构
+type Message struct {
+	Type    string              `json:"type"`              // 消息类型，服务端按类型分发给处理器
+	ID      string              `json:"id,omitempty"`      // 客户端消息ID，错误回复中原样返回
+	Code    common.BusinessCode `json:"code"`              // 业务状态码，0 表示成功
+	Message string              `json:"message,omitempty"` // 错误消息
+	Data    json.RawMessage     `json:"data,omitempty"`    // 消息数据
+	Time    int64               `json:"time"`              // 发送时间（Unix 毫秒）
+}
+
+// NewMessage 创建消息，data 编码为 JSON
+func NewMessage(msgType string, data interface{}) (*Message, error) {
+	msg := &Message{Type: msgType, Time: time.Now().UnixMilli()}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		msg.Data = raw
+	}
+	return msg, nil
+}
+
+// Decode 将消息数据解码到 v
+func (m *Message) Decode(v interface{}) error {
+	if len(m.Data) == 0 {
+		return common.NewError(common.CodeWSMessageInvalid)
+	}
+	if err := json.Unmarshal(m.Data, v); err != nil {
+		return common.WrapError(err, common.CodeWSMessageInvalid)
+	}
+	return nil
+}
+
+// errorMessage 创建错误回复，消息按连接的语言本地化
+func errorMessage(id string, err *common.Error, locale string) *Message {
+	message := err.Message
+	if message == "" {
+		message = common.GetLocaleMessage(locale, err.Code, err.Params)
+	}
+	msg := &Message{Type: TypeError, ID: id, Code: err.Code, Message: message, Time: time.Now().UnixMilli()}
+	if err.Details != nil {
+		msg.Data, _ = json.Marshal(err.Details)
+	}
+	return msg
+}
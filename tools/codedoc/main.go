@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-template/utils/common"
+)
+
+const (
+	// 默认多语言消息目录
+	DEFAULT_LOCALES = "../../locales"
+	// 默认输出路径
+	DEFAULT_OUTPUT = "../../docs/business-codes.md"
+)
+
+// 生成状态码表文档
+func generate(localesDir, outputPath string) error {
+	catalog := common.DefaultCatalog()
+	if localesDir != "" {
+		if err := catalog.LoadDir(localesDir); err != nil {
+			return fmt.Errorf("加载多语言消息目录失败 %s: %v", localesDir, err)
+		}
+	}
+	locales := catalog.Locales()
+
+	var b strings.Builder
+	b.WriteString("# 业务状态码表\n\n")
+	b.WriteString("> 本文件由 `task codes:generate` 根据 `utils/common` 中注册的状态码生成，请勿手动修改。\n\n")
+
+	b.WriteString("## 模块区间\n\n")
+	b.WriteString("| 模块 | 区间 |\n")
+	b.WriteString("|------|------|\n")
+	for _, module := range common.DefaultRegistry().Modules() {
+		fmt.Fprintf(&b, "| %s | %d - %d |\n", module.Name, module.Min, module.Max)
+	}
+
+	b.WriteString("\n## 状态码\n\n")
+	b.WriteString("| 状态码 | 模块 | HTTP | 可重试 |")
+	for _, locale := range locales {
+		fmt.Fprintf(&b, " %s |", locale)
+	}
+	b.WriteString("\n|--------|------|------|--------|")
+	for range locales {
+		b.WriteString("------|")
+	}
+	b.WriteString("\n")
+
+	codes := common.DefaultRegistry().Codes()
+	for _, info := range codes {
+		retryable := "否"
+		if info.Retryable {
+			retryable = "是"
+		}
+		fmt.Fprintf(&b, "| %d | %s | %d | %s |", info.Code, info.Module, info.HTTPStatus, retryable)
+		for _, locale := range locales {
+			fmt.Fprintf(&b, " %s |", catalog.Message(locale, info.Code, nil))
+		}
+		b.WriteString("\n")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %v", err)
+	}
+
+	if err := os.WriteFile(outputPath, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("写入文件失败 %s: %v", outputPath, err)
+	}
+
+	fmt.Printf("✅ 已生成 %d 个业务状态码: %s\n", len(codes), outputPath)
+	return nil
+}
+
+func main() {
+	localesDir := flag.String("locales", DEFAULT_LOCALES, "多语言消息目录，为空时只输出中文消息")
+	outputPath := flag.String("out", DEFAULT_OUTPUT, "文档输出路径")
+	flag.Parse()
+
+	if err := generate(*localesDir, *outputPath); err != nil {
+		log.Fatal(err)
+	}
+}
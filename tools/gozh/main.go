@@ -17,6 +17,7 @@ type AppTemplate struct {
 	ModulePath   string // Go模块路径
 	AppPath      string // 应用路径，如 "app/ticketing/admin"
 	ImportPrefix string // 导入路径前缀
+	GRPC         bool   // 是否生成gRPC服务器
 }
 
 // 文件模板定义
@@ -24,6 +25,7 @@ type FileTemplate struct {
 	Path    string // 文件相对路径
 	Content string // 文件内容模板
 	IsDir   bool   // 是否为目录
+	GRPC    bool   // 是否仅在启用 -grpc 时生成
 }
 
 // 获取应用模板
@@ -37,6 +39,7 @@ func getAppTemplates() []FileTemplate {
 		{Path: "internal/handler", IsDir: true},
 		{Path: "internal/server", IsDir: true},
 		{Path: "internal/server/http", IsDir: true},
+		{Path: "internal/server/grpc", IsDir: true, GRPC: true},
 
 		// cmd/main.go
 		{Path: "cmd/main.go", Content: cmdMainTemplate},
@@ -56,6 +59,9 @@ func getAppTemplates() []FileTemplate {
 		// internal/server/http/server.go
 		{Path: "internal/server/http/server.go", Content: httpServerTemplate},
 
+		// internal/server/grpc/server.go
+		{Path: "internal/server/grpc/server.go", Content: grpcServerTemplate, GRPC: true},
+
 		// README.md
 		{Path: "README.md", Content: readmeTemplate},
 	}
@@ -67,20 +73,25 @@ func main() {
 		fmt.Println("示例: gozh app/ticketing/admin")
 		fmt.Println("      gozh app/ticketing/order")
 		fmt.Println("      gozh app/user/service -module=custom-module")
+		fmt.Println("      gozh app/user/service -grpc")
 		fmt.Println("")
 		fmt.Println("选项:")
 		fmt.Println("  -module=<模块名>  手动指定Go模块名（覆盖自动检测）")
+		fmt.Println("  -grpc             同时生成gRPC服务器 (internal/server/grpc)")
 		os.Exit(1)
 	}
 
 	appPath := strings.TrimSuffix(os.Args[1], "/")
 	var customModule string
+	var enableGRPC bool
 
 	// 解析命令行参数
 	for i := 2; i < len(os.Args); i++ {
 		arg := os.Args[i]
 		if strings.HasPrefix(arg, "-module=") {
 			customModule = strings.TrimPrefix(arg, "-module=")
+		} else if arg == "-grpc" {
+			enableGRPC = true
 		}
 	}
 
@@ -100,6 +111,7 @@ func main() {
 	if err != nil {
 		log.Fatalf("解析应用路径失败: %v", err)
 	}
+	appTemplate.GRPC = enableGRPC
 
 	// 构建完整的目标路径（相对于项目根目录）
 	targetPath := filepath.Join(projectInfo.RootDir, appPath)
@@ -131,6 +143,9 @@ func main() {
 	fmt.Printf("   ├── internal/data/       (数据层)\n")
 	fmt.Printf("   ├── internal/handler/    (处理器层)\n")
 	fmt.Printf("   ├── internal/server/     (服务器层)\n")
+	if enableGRPC {
+		fmt.Printf("   │   └── grpc/            (gRPC服务器)\n")
+	}
 	fmt.Printf("   └── README.md            (说明文档)\n")
 }
 
@@ -208,6 +223,9 @@ func generateApp(appPath string, appTemplate *AppTemplate) error {
 	templates := getAppTemplates()
 
 	for _, tmpl := range templates {
+		if tmpl.GRPC && !appTemplate.GRPC {
+			continue
+		}
 		targetPath := filepath.Join(appPath, tmpl.Path)
 
 		if tmpl.IsDir {
@@ -264,21 +282,26 @@ func renderTemplate(templateContent string, data *AppTemplate) (string, error) {
 const cmdMainTemplate = `package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
+	"time"
 
 	"{{.ImportPrefix}}/config"
 	"{{.ImportPrefix}}/internal/data"
 	"{{.ImportPrefix}}/internal/handler"
 	"{{.ImportPrefix}}/internal/server"
+	"{{.ModulePath}}/pkg/app"
+	"{{.ModulePath}}/pkg/health"
 	"{{.ModulePath}}/pkg/helper"
 	"{{.ModulePath}}/pkg/jaeger"
 	"{{.ModulePath}}/pkg/mysql"
 	"{{.ModulePath}}/pkg/prometheus"
 	"{{.ModulePath}}/pkg/redis"
+	"{{.ModulePath}}/utils/common"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
 )
 
 // @title {{.AppName}}服务API
@@ -300,6 +323,7 @@ import (
 func main() {
 	// 解析命令行参数
 	configFile := flag.String("config", "config.toml", "配置文件路径")
+	envFile := flag.String("env", ".env", "环境变量文件路径")
 	flag.Parse()
 
 	// 加载配置
@@ -308,36 +332,23 @@ func main() {
 	if _, err := os.Stat(*configFile); os.IsNotExist(err) {
 		cfg = config.DefaultConfig()
 	} else {
-		cfg, err = config.LoadConfig(*configFile)
+		cfg, err = config.LoadConfig(*configFile, *envFile)
 		if err != nil {
 			cfg = config.DefaultConfig()
 		}
 	}
 
 	// 创建应用程序实例
-	app, cleanup, err := initApp(cfg)
+	application, err := initApp(cfg)
 	if err != nil {
 		panic(fmt.Sprintf("初始化应用程序失败: %v", err))
 	}
 
-	// 设置优雅关闭
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	// goroutine 中启动服务器
-	go func() {
-		if err := app.Run(); err != nil {
-			panic(fmt.Sprintf("服务器启动失败: %v", err))
-		}
-	}()
-
-	// 等待关闭信号
-	<-quit
-	fmt.Println("正在关闭服务...")
-
-	// 清理资源
-	cleanup()
-	fmt.Println("服务已关闭")
+	// 启动服务并阻塞，收到 SIGINT/SIGTERM 后优雅关闭
+	if err := application.Run(context.Background()); err != nil {
+		application.Logger.Error("应用程序异常退出", "error", err)
+		os.Exit(1)
+	}
 }
 
 // App 应用程序结构
@@ -348,38 +359,91 @@ type App struct {
 	ServerProvider  *server.ServerProvider
 	Metrics         *prometheus.Metrics
 	Tracer          *jaeger.TracingProvider
+	Health          *health.Registry
+	Lifecycle       *app.App
+	Logger          *zhlog.Helper
 }
 
 // initApp 初始化应用程序
-func initApp(cfg *config.Config) (*App, func(), error) {
+// 基础设施以组件形式注册到生命周期管理器，由 App.Run 按依赖顺序启动（失败时按退避策略重试）并逆序停止
+func initApp(cfg *config.Config) (*App, error) {
 	// 创建日志记录器
 	logger := helper.NewSimpleLogger()
 
-	// 创建数据库连接
-	db := mysql.NewMySQL(&mysql.MySQLConfig{
+	// 检查配置，错误的超时时间等配置直接终止启动，避免静默使用默认值
+	if err := cfg.Validate(); err != nil {
+		logger.Error("配置无效", "error", err)
+		return nil, err
+	}
+
+	// 创建Prometheus监控
+	metrics, err := prometheus.NewMetrics(&prometheus.PrometheusConfig{
+		Namespace:   cfg.Monitoring.PrometheusNamespace,
+		Subsystem:   cfg.Monitoring.PrometheusSubsystem,
+		MetricsPath: cfg.Monitoring.MetricsPath,
+		SLOs:        cfg.Monitoring.SLOs,
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// 加载多语言消息目录，未配置的语言和状态码回退到内置中文消息
+	if cfg.I18n.Dir != "" {
+		if err := common.DefaultCatalog().LoadDir(cfg.I18n.Dir); err != nil {
+			logger.Warn("加载多语言消息目录失败", "dir", cfg.I18n.Dir, "error", err)
+		}
+	}
+
+	// 创建健康检查
+	healthRegistry := health.NewRegistry(&health.HealthConfig{
+		Namespace: cfg.Monitoring.PrometheusNamespace,
+		Subsystem: cfg.Monitoring.PrometheusSubsystem,
+	}, logger)
+	if err := metrics.RegisterMetrics(healthRegistry.Collectors()...); err != nil {
+		return nil, err
+	}
+
+	// 创建生命周期管理器
+	lifecycle := app.New(&app.AppConfig{
+		DrainPeriod:     config.ParseDuration(cfg.App.DrainPeriod, 5*time.Second),
+		ShutdownTimeout: config.ParseDuration(cfg.App.ShutdownTimeout, 10*time.Second),
+		StartTimeout:    config.ParseDuration(cfg.App.StartTimeout, 30*time.Second),
+	}, healthRegistry, logger)
+
+	// MySQL 组件
+	mysqlComponent, err := mysql.NewComponent(&mysql.MySQLConfig{
 		Host:     cfg.Database.Host,
 		Port:     cfg.Database.Port,
 		User:     cfg.Database.User,
 		Password: cfg.Database.Password,
 		DBName:   cfg.Database.DBName,
 	}, logger)
+	if err != nil {
+		return nil, err
+	}
+	lifecycle.MustRegister(mysqlComponent)
 
-	// 创建Redis连接
-	rdb := redis.NewRedis(&redis.RedisConfig{
+	// Redis 组件
+	redisComponent := redis.NewComponent(&redis.RedisConfig{
 		Host:     cfg.Redis.Host,
 		Port:     cfg.Redis.Port,
 		Password: cfg.Redis.Password,
 		DB:       cfg.Redis.DB,
 	}, logger)
+	lifecycle.MustRegister(redisComponent)
 
-	// 创建Prometheus监控
-	metrics := prometheus.NewMetrics(prometheus.DefaultConfig("{{.AppName}}"), logger)
+	db := mysqlComponent.DB()
+	rdb := redisComponent.Client()
+	healthRegistry.Register(mysql.HealthCheck(db), redis.HealthCheck(rdb))
 
 	// 创建Jaeger链路追踪
 	tracer, err := jaeger.NewTracingProvider(jaeger.DefaultConfig("{{.AppName}}-service"), logger)
 	if err != nil {
 		logger.Error("Failed to initialize tracing", "error", err)
 		// 继续运行，但不使用追踪
+	} else {
+		lifecycle.MustRegister(tracer)
+		healthRegistry.Register(tracer.HealthCheck())
 	}
 
 	// 创建数据提供者
@@ -389,49 +453,41 @@ func initApp(cfg *config.Config) (*App, func(), error) {
 	handlerProvider := handler.NewHandlerProvider(dataProvider, logger)
 
 	// 创建服务器提供者
-	serverProvider := server.NewServerProvider(handlerProvider, logger, metrics, tracer)
+	serverProvider, err := server.NewServerProvider(cfg, handlerProvider, logger, metrics, tracer, healthRegistry)
+	if err != nil {
+		return nil, err
+	}
 
-	app := &App{
+	// HTTP服务器在依赖就绪后启动，关闭时最先停止
+	lifecycle.MustRegister(
+		app.NewHTTPServer("http", serverProvider.HTTPServer.Server(cfg.Server.Port)),
+		app.DependsOn(mysqlComponent.Name(), redisComponent.Name()),
+	)
+{{- if .GRPC}}
+
+	// gRPC服务器与HTTP服务器由同一生命周期管理器启动和优雅关闭
+	lifecycle.MustRegister(
+		app.NewGRPCServer("grpc", serverProvider.GRPCServer.Server(), ":"+cfg.Server.GRPCPort),
+		app.DependsOn(mysqlComponent.Name(), redisComponent.Name()),
+	)
+{{- end}}
+
+	return &App{
 		Config:          cfg,
 		DataProvider:    dataProvider,
 		HandlerProvider: handlerProvider,
 		ServerProvider:  serverProvider,
 		Metrics:         metrics,
 		Tracer:          tracer,
-	}
-
-	// 返回清理函数
-	cleanup := func() {
-		if db != nil {
-			if sqlDB, err := db.DB(); err == nil {
-				if err := sqlDB.Close(); err != nil {
-					logger.Error("关闭数据库连接失败", "error", err)
-				}
-			}
-		}
-
-		if rdb != nil {
-			if err := rdb.Close(); err != nil {
-				logger.Error("关闭Redis连接失败", "error", err)
-			}
-		}
-
-		if tracer != nil {
-			if err := tracer.Shutdown(context.Background()); err != nil {
-				logger.Error("关闭链路追踪失败", "error", err)
-			}
-		}
-
-		logger.Info("应用程序资源已清理")
-	}
-
-	return app, cleanup, nil
+		Health:          healthRegistry,
+		Lifecycle:       lifecycle,
+		Logger:          logger,
+	}, nil
 }
 
-// Run 启动应用程序
-func (app *App) Run() error {
-	// 启动HTTP服务器
-	return app.ServerProvider.HTTPServer.Start(app.Config.Server.Port)
+// Run 启动应用程序，阻塞直到优雅关闭完成
+func (a *App) Run(ctx context.Context) error {
+	return a.Lifecycle.Run(ctx)
 }
 `
 
@@ -439,13 +495,19 @@ func (app *App) Run() error {
 const configTemplate = `package config
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/joho/godotenv"
+
+	"{{.ModulePath}}/pkg/etcd"
+	"{{.ModulePath}}/pkg/middleware"
+	"{{.ModulePath}}/pkg/prometheus"
 )
 
 // Config 应用配置
@@ -459,12 +521,17 @@ type Config struct {
 	Security   SecurityConfig   ` + "`toml:\"security\"`" + `
 	App        AppConfig        ` + "`toml:\"app\"`" + `
 	Cache      CacheConfig      ` + "`toml:\"cache\"`" + `
+	CORS       middleware.CORSConfig ` + "`toml:\"cors\"`" + `
+	I18n       I18nConfig       ` + "`toml:\"i18n\"`" + `
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
 	Port string ` + "`toml:\"port\"`" + `
 	Mode string ` + "`toml:\"mode\"`" + `
+{{- if .GRPC}}
+	GRPCPort string ` + "`toml:\"grpc_port\"`" + `
+{{- end}}
 }
 
 // DatabaseConfig 数据库配置
@@ -503,9 +570,36 @@ type EtcdConfig struct {
 	DialTimeout        int    ` + "`toml:\"dial_timeout\"`" + `
 	KeepAliveTime      string ` + "`toml:\"keep_alive_time\"`" + `
 	KeepAliveTimeout   string ` + "`toml:\"keep_alive_timeout\"`" + `
+	AutoSyncInterval   string ` + "`toml:\"auto_sync_interval\"`" + `
+	MaxCallSendMsgSize int    ` + "`toml:\"max_call_send_msg_size\"`" + `
+	MaxCallRecvMsgSize int    ` + "`toml:\"max_call_recv_msg_size\"`" + `
 	TLSEnabled         bool   ` + "`toml:\"tls_enabled\"`" + `
+	CertFile           string ` + "`toml:\"cert_file\"`" + `
+	KeyFile            string ` + "`toml:\"key_file\"`" + `
+	CAFile             string ` + "`toml:\"ca_file\"`" + `
 	// 敏感信息从环境变量获取
 	Endpoints []string
+	Username  string
+	Password  string
+}
+
+// Client 转换为 pkg/etcd 的客户端配置
+func (c EtcdConfig) Client() *etcd.EtcdConfig {
+	return &etcd.EtcdConfig{
+		Endpoints:          c.Endpoints,
+		DialTimeout:        c.DialTimeout,
+		Username:           c.Username,
+		Password:           c.Password,
+		TLSEnabled:         c.TLSEnabled,
+		CertFile:           c.CertFile,
+		KeyFile:            c.KeyFile,
+		CAFile:             c.CAFile,
+		KeepAliveTime:      ParseDuration(c.KeepAliveTime, 30*time.Second),
+		KeepAliveTimeout:   ParseDuration(c.KeepAliveTimeout, 10*time.Second),
+		AutoSyncInterval:   ParseDuration(c.AutoSyncInterval, 0),
+		MaxCallSendMsgSize: c.MaxCallSendMsgSize,
+		MaxCallRecvMsgSize: c.MaxCallRecvMsgSize,
+	}
 }
 
 // MonitoringConfig 监控配置
@@ -519,6 +613,8 @@ type MonitoringConfig struct {
 	JaegerURL           string  ` + "`toml:\"jaeger_url\"`" + `
 	JaegerSampleRatio   float64 ` + "`toml:\"jaeger_sample_ratio\"`" + `
 	JaegerDisabled      bool    ` + "`toml:\"jaeger_disabled\"`" + `
+	// 服务等级目标，对应配置文件中的 [[monitoring.slo]]
+	SLOs []prometheus.SLOConfig ` + "`toml:\"slo\"`" + `
 }
 
 // LoggingConfig 日志配置
@@ -549,6 +645,10 @@ type AppConfig struct {
 	Version         string ` + "`toml:\"version\"`" + `
 	RequestTimeout  string ` + "`toml:\"request_timeout\"`" + `
 	ShutdownTimeout string ` + "`toml:\"shutdown_timeout\"`" + `
+	DrainPeriod     string ` + "`toml:\"drain_period\"`" + `
+	StartTimeout    string ` + "`toml:\"start_timeout\"`" + `
+	// 按路由覆盖请求超时时间，key 为 "GET /api/v1/users/:id" 或 "/api/v1/export"
+	RouteTimeouts map[string]string ` + "`toml:\"route_timeouts\"`" + `
 }
 
 // CacheConfig 缓存配置
@@ -558,6 +658,11 @@ type CacheConfig struct {
 	MaxMemory       string ` + "`toml:\"max_memory\"`" + `
 }
 
+// I18nConfig 多语言配置
+type I18nConfig struct {
+	Dir string ` + "`toml:\"dir\"`" + ` // 消息目录文件所在目录，文件名即语言，如 en.toml、ja.json
+}
+
 // LoadConfig 加载配置文件
 func LoadConfig(configFile, envFile string) (*Config, error) {
 	// 加载环境变量
@@ -599,6 +704,8 @@ func loadSensitiveConfig(config *Config) {
 	if endpoints := getEnv("ETCD_ENDPOINTS", ""); endpoints != "" {
 		config.Etcd.Endpoints = strings.Split(endpoints, ",")
 	}
+	config.Etcd.Username = getEnv("ETCD_USERNAME", "")
+	config.Etcd.Password = getEnv("ETCD_PASSWORD", "")
 
 	// 安全敏感信息
 	config.Security.JWTSecret = getEnv("JWT_SECRET", "your-secret-key-here")
@@ -618,6 +725,37 @@ func loadSensitiveConfig(config *Config) {
 
 	// 服务器模式
 	config.Server.Mode = getEnv("SERVER_MODE", config.Server.Mode)
+
+	// 跨域来源，多个来源以逗号分隔
+	if origins := getEnv("CORS_ALLOWED_ORIGINS", ""); origins != "" {
+		config.CORS.AllowedOrigins = strings.Split(origins, ",")
+	}
+}
+
+// ParseDuration 解析配置中的时间字符串，解析失败时使用默认值
+func ParseDuration(value string, defaultValue time.Duration) time.Duration {
+	if d, err := time.ParseDuration(value); err == nil {
+		return d
+	}
+	return defaultValue
+}
+
+// Validate 检查配置中不能回退到默认值的字段，如按路由覆盖的请求超时时间和跨域策略
+func (c *Config) Validate() error {
+	if c.App.RequestTimeout != "" {
+		if _, err := time.ParseDuration(c.App.RequestTimeout); err != nil {
+			return fmt.Errorf("config: invalid app.request_timeout %q: %w", c.App.RequestTimeout, err)
+		}
+	}
+	for route, timeout := range c.App.RouteTimeouts {
+		if _, err := time.ParseDuration(timeout); err != nil {
+			return fmt.Errorf("config: invalid app.route_timeouts[%q] %q: %w", route, timeout, err)
+		}
+	}
+	if err := c.CORS.Validate(); err != nil {
+		return fmt.Errorf("config: invalid [cors]: %w", err)
+	}
+	return nil
 }
 
 // getEnv 获取环境变量，如果不存在则返回默认值
@@ -634,6 +772,9 @@ func DefaultConfig() *Config {
 		Server: ServerConfig{
 			Port: "8080",
 			Mode: "debug",
+{{- if .GRPC}}
+			GRPCPort: "9090",
+{{- end}}
 		},
 		Database: DatabaseConfig{
 			MaxOpenConns:    25,
@@ -700,6 +841,8 @@ func DefaultConfig() *Config {
 			Version:         "1.0.0",
 			RequestTimeout:  "30s",
 			ShutdownTimeout: "10s",
+			DrainPeriod:     "5s",
+			StartTimeout:    "30s",
 		},
 		Cache: CacheConfig{
 			DefaultTTL:      "1h",
@@ -714,6 +857,10 @@ func DefaultConfig() *Config {
 const dataProviderTemplate = `package data
 
 import (
+	"context"
+
+	"{{.ModulePath}}/pkg/repository"
+
 	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
@@ -723,18 +870,36 @@ import (
 type DataProvider struct {
 	mySQL *gorm.DB
 	redis *redis.Client
+	tx    *repository.TxManager
 	log   *zhlog.Helper
 }
 
 // NewDataProvider 创建数据提供者
 func NewDataProvider(mysql *gorm.DB, redis *redis.Client, log *zhlog.Helper) *DataProvider {
-	return &DataProvider{mySQL: mysql, redis: redis, log: log}
+	return &DataProvider{mySQL: mysql, redis: redis, tx: repository.NewTxManager(mysql), log: log}
+}
+
+// DB 返回绑定请求上下文的数据库会话，请求超时或取消时查询会被中断
+// ctx 中存在 WithinTx 开启的事务时返回该事务
+func (d *DataProvider) DB(ctx context.Context) *gorm.DB {
+	return repository.DB(ctx, d.mySQL)
+}
+
+// Tx 返回事务管理器，通过 WithinTx 让多个仓库共享同一事务
+func (d *DataProvider) Tx() *repository.TxManager {
+	return d.tx
+}
+
+// Redis 返回Redis客户端，调用命令时请传入请求上下文以遵守请求截止时间
+func (d *DataProvider) Redis() *redis.Client {
+	return d.redis
 }
 
 // TODO: 在这里添加您的数据仓库提供方法
+// 通用的增删改查可直接使用 repository.NewRepository[T]，自定义查询通过 d.DB(ctx) 访问数据库
 // 示例:
-// func (d *DataProvider) ProvideUserRepo() *user.UserRepo {
-//     return user.NewUserRepo(d.mySQL, d.redis, d.log)
+// func (d *DataProvider) ProvideUserRepo() *repository.Repository[model.User] {
+//     return repository.NewRepository[model.User](d.mySQL)
 // }
 `
 
@@ -766,14 +931,37 @@ func NewHandlerProvider(dataProvider *data.DataProvider, log *zhlog.Helper) *Han
 // func (h *HandlerProvider) ProvideUserHandler() *user.UserHandler {
 //     return user.NewUserHandler(h.data.ProvideUserRepo(), h.log)
 // }
+//
+// 处理器出错时调用 c.Error(err) 并返回，由错误处理中间件输出统一响应:
+//     var req CreateUserRequest // 字段使用 json/form/uri/header 标签绑定，validate 标签校验
+//     if err := common.Bind(c, &req); err != nil {
+//         _ = c.Error(err) // 校验失败返回 CodeValidationFailed 及字段错误列表
+//         return
+//     }
+//     user, err := repo.Get(c.Request.Context(), id)
+//     if err != nil {
+//         _ = c.Error(err) // gorm.ErrRecordNotFound 自动映射为 CodeNotFound
+//         return
+//     }
+//     if user.Disabled {
+//         _ = c.Error(common.NewError(common.CodeForbidden).WithMessage("账号已被禁用"))
+//         return
+//     }
 `
 
 // internal/server/provider.go 模板
 const serverProviderTemplate = `package server
 
 import (
+	"{{.ImportPrefix}}/config"
 	"{{.ImportPrefix}}/internal/handler"
+{{- if .GRPC}}
+	"{{.ImportPrefix}}/internal/server/grpc"
+{{- end}}
 	"{{.ImportPrefix}}/internal/server/http"
+	"{{.ModulePath}}/pkg/health"
+	"{{.ModulePath}}/pkg/jaeger"
+	"{{.ModulePath}}/pkg/prometheus"
 
 	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
 )
@@ -781,21 +969,38 @@ import (
 // ServerProvider 服务器提供者
 type ServerProvider struct {
 	HTTPServer *http.HTTPServer
+{{- if .GRPC}}
+	GRPCServer *grpc.GRPCServer
+{{- end}}
 	log        *zhlog.Helper
 }
 
 // NewServerProvider 创建服务器提供者
-func NewServerProvider(handlerProvider *handler.HandlerProvider, log *zhlog.Helper, metrics *prometheus.Metrics, tracer *jaeger.TracingProvider) *ServerProvider {
+func NewServerProvider(cfg *config.Config, handlerProvider *handler.HandlerProvider, log *zhlog.Helper, metrics *prometheus.Metrics, tracer *jaeger.TracingProvider, healthRegistry *health.Registry) (*ServerProvider, error) {
 	// 创建HTTP服务器
-	httpServer := http.NewHTTPServer(handlerProvider, log, metrics, tracer)
+	httpServer, err := http.NewHTTPServer(cfg, handlerProvider, log, metrics, tracer, healthRegistry)
+	if err != nil {
+		return nil, err
+	}
 
 	// 设置路由
 	httpServer.SetupRoutes()
+{{- if .GRPC}}
+
+	// 创建gRPC服务器
+	grpcServer := grpc.NewGRPCServer(cfg, handlerProvider, log, metrics, tracer, healthRegistry)
+
+	// 注册服务
+	grpcServer.RegisterServices()
+{{- end}}
 
 	return &ServerProvider{
 		HTTPServer: httpServer,
+{{- if .GRPC}}
+		GRPCServer: grpcServer,
+{{- end}}
 		log:        log,
-	}
+	}, nil
 }
 `
 
@@ -805,9 +1010,13 @@ const httpServerTemplate = `package http
 import (
 	"fmt"
 	"net/http"
+	"time"
 
+	"{{.ImportPrefix}}/config"
 	"{{.ImportPrefix}}/internal/handler"
+	"{{.ModulePath}}/pkg/health"
 	"{{.ModulePath}}/pkg/jaeger"
+	"{{.ModulePath}}/pkg/middleware"
 	"{{.ModulePath}}/pkg/prometheus"
 	"{{.ModulePath}}/utils/common"
 
@@ -821,12 +1030,17 @@ type HTTPServer struct {
 	handler *handler.HandlerProvider
 	metrics *prometheus.Metrics
 	tracer  *jaeger.TracingProvider
+	health  *health.Registry
 	log     *zhlog.Helper
 }
 
 // NewHTTPServer 创建HTTP服务器
-func NewHTTPServer(handlerProvider *handler.HandlerProvider, log *zhlog.Helper, metrics *prometheus.Metrics, tracer *jaeger.TracingProvider) *HTTPServer {
-	router := gin.Default()
+func NewHTTPServer(cfg *config.Config, handlerProvider *handler.HandlerProvider, log *zhlog.Helper, metrics *prometheus.Metrics, tracer *jaeger.TracingProvider, healthRegistry *health.Registry) (*HTTPServer, error) {
+	router := gin.New()
+	router.Use(gin.Logger())
+
+	// 添加请求ID中间件
+	router.Use(middleware.RequestID())
 
 	// 添加链路追踪中间件
 	if tracer != nil {
@@ -838,27 +1052,42 @@ func NewHTTPServer(handlerProvider *handler.HandlerProvider, log *zhlog.Helper,
 		router.Use(metrics.GinMiddleware())
 	}
 
-	// 添加CORS中间件
-	router.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	// 添加panic恢复中间件，panic时返回统一的错误响应
+	router.Use(middleware.Recovery(&middleware.RecoveryConfig{
+		Logger:  log,
+		Metrics: metrics,
+	}))
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusNoContent)
-			return
-		}
+	// 添加错误处理中间件，处理器通过 c.Error(err) 返回的错误会被转换为统一的业务响应
+	router.Use(middleware.ErrorHandler(&middleware.ErrorHandlerConfig{Logger: log}))
 
-		c.Next()
-	})
+	// 添加CORS中间件，跨域策略由配置文件中的 [cors] 段控制
+	cors, err := middleware.CORS(&cfg.CORS)
+	if err != nil {
+		return nil, err
+	}
+	router.Use(cors)
+
+	// 添加请求超时中间件，处理器需将 c.Request.Context() 传递给数据库、缓存和外部调用
+	// 超时时间已在启动时由 cfg.Validate 检查
+	routeTimeouts := make(map[string]time.Duration, len(cfg.App.RouteTimeouts))
+	for route, timeout := range cfg.App.RouteTimeouts {
+		routeTimeouts[route] = config.ParseDuration(timeout, 0)
+	}
+	router.Use(middleware.Timeout(&middleware.TimeoutConfig{
+		Timeout: config.ParseDuration(cfg.App.RequestTimeout, 30*time.Second),
+		Routes:  routeTimeouts,
+		Metrics: metrics,
+	}))
 
 	return &HTTPServer{
 		router:  router,
 		handler: handlerProvider,
 		metrics: metrics,
 		tracer:  tracer,
+		health:  healthRegistry,
 		log:     log,
-	}
+	}, nil
 }
 
 // SetupRoutes 设置路由
@@ -868,11 +1097,23 @@ func (s *HTTPServer) SetupRoutes() {
 		s.router.GET("/metrics", gin.WrapH(s.metrics.Handler()))
 	}
 
+	// Kubernetes 探针端点: /livez、/readyz、/healthz
+	s.health.RegisterRoutes(s.router)
+
 	api := s.router.Group("/api/v1")
 
+	// 业务状态码表，消息按请求语言本地化
+	api.GET("/codes", common.CodeTableHandler())
+
 	// 健康检查
 	api.GET("/health", func(c *gin.Context) {
-		common.SuccessResponseFunc(c, "服务正常", gin.H{"status": "ok", "service": "{{.AppName}}"})
+		report := s.health.Check(c.Request.Context())
+		data := gin.H{"status": report.Status, "service": "{{.AppName}}", "checks": report.Checks}
+		if report.Status == health.StatusFail {
+			c.JSON(http.StatusServiceUnavailable, common.GetBusinessResponse(common.CodeServiceBusy, data))
+			return
+		}
+		common.SuccessResponseFunc(c, "服务正常", data)
 	})
 
 	// TODO: 在这里添加您的路由
@@ -888,10 +1129,128 @@ func (s *HTTPServer) SetupRoutes() {
 	// }
 }
 
-// Start 启动服务器
-func (s *HTTPServer) Start(port string) error {
-	s.log.Info("启动HTTP服务器", "port", port)
-	return s.router.Run(fmt.Sprintf(":%s", port))
+// Server 创建标准库HTTP服务器，由应用生命周期管理器负责启动和优雅关闭
+func (s *HTTPServer) Server(port string) *http.Server {
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%s", port),
+		Handler:           s.router,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+`
+
+// internal/server/grpc/server.go 模板
+const grpcServerTemplate = `package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"{{.ImportPrefix}}/config"
+	"{{.ImportPrefix}}/internal/handler"
+	"{{.ModulePath}}/pkg/health"
+	"{{.ModulePath}}/pkg/interceptor"
+	"{{.ModulePath}}/pkg/jaeger"
+	"{{.ModulePath}}/pkg/prometheus"
+	"{{.ModulePath}}/utils/common"
+
+	zhlog "codeup.aliyun.com/chevalierteam/zhanhai-kit/plugins/logger/zap"
+)
+
+// GRPCServer gRPC服务器
+type GRPCServer struct {
+	server  *grpc.Server
+	handler *handler.HandlerProvider
+	health  *health.Registry
+	mode    string
+	log     *zhlog.Helper
+}
+
+// NewGRPCServer 创建gRPC服务器
+func NewGRPCServer(cfg *config.Config, handlerProvider *handler.HandlerProvider, log *zhlog.Helper, metrics *prometheus.Metrics, tracer *jaeger.TracingProvider, healthRegistry *health.Registry) *GRPCServer {
+	// 最外层的panic恢复和错误转换拦截器，防止其他拦截器panic导致进程退出
+	recovery := &interceptor.RecoveryConfig{Logger: log, Metrics: metrics}
+	unary := []grpc.UnaryServerInterceptor{interceptor.UnaryRecovery(recovery), interceptor.UnaryErrors()}
+	stream := []grpc.StreamServerInterceptor{interceptor.StreamRecovery(recovery), interceptor.StreamErrors()}
+
+	// 添加请求ID拦截器
+	unary = append(unary, interceptor.UnaryRequestID())
+	stream = append(stream, interceptor.StreamRequestID())
+
+	// 添加链路追踪拦截器
+	if tracer != nil {
+		unary = append(unary, tracer.UnaryServerInterceptor())
+		stream = append(stream, tracer.StreamServerInterceptor())
+	}
+
+	// 添加Prometheus监控拦截器
+	if metrics != nil {
+		unary = append(unary, metrics.UnaryServerInterceptor())
+		stream = append(stream, metrics.StreamServerInterceptor())
+	}
+
+	// 添加错误转换拦截器，处理器返回的 common.Error 会被转换为带业务状态码详情的 gRPC 状态
+	unary = append(unary, interceptor.UnaryErrors())
+	stream = append(stream, interceptor.StreamErrors())
+
+	// 添加调用日志拦截器，位于错误转换之内以记录原始错误
+	logging := &interceptor.LoggingConfig{Logger: log}
+	unary = append(unary, interceptor.UnaryLogging(logging))
+	stream = append(stream, interceptor.StreamLogging(logging))
+
+	// 添加处理器的panic恢复拦截器，panic时返回 Internal 状态，日志中包含请求ID
+	unary = append(unary, interceptor.UnaryRecovery(recovery))
+	stream = append(stream, interceptor.StreamRecovery(recovery))
+
+	// 添加认证拦截器，健康检查和反射服务不需要认证
+	auth := &interceptor.AuthConfig{Authenticate: authenticate(cfg)}
+	unary = append(unary, interceptor.UnaryAuth(auth))
+	stream = append(stream, interceptor.StreamAuth(auth))
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+
+	return &GRPCServer{
+		server:  server,
+		handler: handlerProvider,
+		health:  healthRegistry,
+		mode:    cfg.Server.Mode,
+		log:     log,
+	}
+}
+
+// RegisterServices 注册服务
+func (s *GRPCServer) RegisterServices() {
+	// 标准健康检查服务 grpc.health.v1.Health，整体状态与 /readyz 一致，服务名为检查项名称时返回该检查项状态
+	healthpb.RegisterHealthServer(s.server, s.health.GRPCHealthServer())
+
+	// 反射服务，便于使用 grpcurl 等工具调试，release 模式下不注册
+	if s.mode != "release" {
+		reflection.Register(s.server)
+	}
+
+	// TODO: 在这里注册您的服务
+	// 示例:
+	// userpb.RegisterUserServiceServer(s.server, s.handler.ProvideUserService())
+}
+
+// Server 返回gRPC服务器，由应用生命周期管理器负责启动和优雅关闭
+func (s *GRPCServer) Server() *grpc.Server {
+	return s.server
+}
+
+// authenticate 返回认证函数
+// TODO: 使用 cfg.Security.JWTSecret 校验 interceptor.BearerToken 返回的 Token，并将用户信息写入 context；
+// 实现之前拒绝所有需要认证的调用，避免服务看似受保护、实际接受任意 Token
+func authenticate(cfg *config.Config) interceptor.AuthFunc {
+	return func(ctx context.Context, fullMethod string) (context.Context, error) {
+		return nil, common.NewError(common.CodeAuthLoginRequired)
+	}
 }
 `
 
@@ -915,8 +1274,15 @@ const readmeTemplate = `# {{.AppName}}服务
 │   │   └── provider.go    # 处理器提供者，管理所有处理器
 │   └── server/            # 服务器层
 │       ├── provider.go    # 服务器提供者，管理所有服务器
+{{- if .GRPC}}
+│       ├── http/          # HTTP服务器实现
+│       │   └── server.go  # HTTP路由和中间件配置
+│       └── grpc/          # gRPC服务器实现
+│           └── server.go  # gRPC拦截器和服务注册
+{{- else}}
 │       └── http/          # HTTP服务器实现
 │           └── server.go  # HTTP路由和中间件配置
+{{- end}}
 └── README.md              # 项目说明文档
 ` + "```" + `
 
@@ -933,6 +1299,14 @@ const readmeTemplate = `# {{.AppName}}服务
 ### 3. 添加新的路由
 
 在 ` + "`internal/server/http/server.go`" + ` 的 ` + "`SetupRoutes`" + ` 方法中添加新的路由。
+{{- if .GRPC}}
+
+### 4. 添加新的gRPC服务
+
+将 protoc 生成的代码放在应用目录下，在 ` + "`internal/server/grpc/server.go`" + ` 的 ` + "`RegisterServices`" + ` 方法中注册服务实现。
+处理器返回的 ` + "`common.Error`" + ` 会被转换为对应的 gRPC 状态码，详情中携带业务状态码；
+认证逻辑在同一文件的 ` + "`authenticate`" + ` 函数中实现。
+{{- end}}
 
 ## 配置说明
 
@@ -944,6 +1318,9 @@ const readmeTemplate = `# {{.AppName}}服务
 [server]
 port = "8080"
 mode = "debug"
+{{- if .GRPC}}
+grpc_port = "9090"
+{{- end}}
 
 [database]
 host = "localhost"
@@ -979,6 +1356,10 @@ go run cmd/main.go -config=custom-config.toml
 
 - Swagger UI: http://localhost:8080/swagger/index.html (如已集成Swagger)
 - 健康检查: http://localhost:8080/api/v1/health
+- Kubernetes 探针: http://localhost:8080/livez 、/readyz 、/healthz (支持 ?verbose 和 ?exclude=<检查项>)
+{{- if .GRPC}}
+- gRPC 健康检查: ` + "`grpcurl -plaintext localhost:9090 grpc.health.v1.Health/Check`" + ` (非 release 模式下启用反射服务)
+{{- end}}
 
 ## 开发注意事项
 
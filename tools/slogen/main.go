@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"go-template/pkg/prometheus"
+)
+
+const (
+	// 默认配置文件路径
+	DEFAULT_CONFIG = "../../config.toml.example"
+	// 默认规则输出路径，与 prometheus.yml 位于同一目录
+	DEFAULT_OUTPUT = "../../monitoring/prometheus/slo_rules.yml"
+)
+
+// sloFileConfig 配置文件中与SLO相关的部分
+type sloFileConfig struct {
+	Monitoring struct {
+		PrometheusNamespace string                 `toml:"prometheus_namespace"`
+		PrometheusSubsystem string                 `toml:"prometheus_subsystem"`
+		SLOs                []prometheus.SLOConfig `toml:"slo"`
+	} `toml:"monitoring"`
+}
+
+// 读取配置文件
+func loadConfig(path string) (*sloFileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败 %s: %v", path, err)
+	}
+
+	var config sloFileConfig
+	if err := toml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败 %s: %v", path, err)
+	}
+
+	return &config, nil
+}
+
+// 生成规则文件
+func generate(configPath, outputPath string) error {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	rules, err := prometheus.GenerateSLORules(&prometheus.PrometheusConfig{
+		Namespace: config.Monitoring.PrometheusNamespace,
+		Subsystem: config.Monitoring.PrometheusSubsystem,
+		SLOs:      config.Monitoring.SLOs,
+	})
+	if err != nil {
+		return fmt.Errorf("生成SLO规则失败: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %v", err)
+	}
+
+	if err := os.WriteFile(outputPath, rules, 0644); err != nil {
+		return fmt.Errorf("写入规则文件失败 %s: %v", outputPath, err)
+	}
+
+	fmt.Printf("✅ 已生成 %d 个SLO的规则: %s\n", len(config.Monitoring.SLOs), outputPath)
+	return nil
+}
+
+func main() {
+	configPath := flag.String("config", DEFAULT_CONFIG, "配置文件路径")
+	outputPath := flag.String("out", DEFAULT_OUTPUT, "规则文件输出路径")
+	flag.Parse()
+
+	if err := generate(*configPath, *outputPath); err != nil {
+		log.Fatal(err)
+	}
+}
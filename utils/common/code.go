@@ -219,12 +219,14 @@ var CodeMessage = map[BusinessCode]string{
 	CodeTransactionError: "事务操作失败",
 }
 
-// GetMessage 获取状态码对应的消息
+// GetMessage 获取状态码对应的默认语言消息
 func GetMessage(code BusinessCode) string {
-	if msg, ok := CodeMessage[code]; ok {
-		return msg
-	}
-	return "未知错误"
+	return defaultCatalog.Message(DefaultLocale, code, nil)
+}
+
+// GetLocaleMessage 获取状态码对应的指定语言消息，并替换消息中的 {name} 占位符
+func GetLocaleMessage(locale string, code BusinessCode, params map[string]interface{}) string {
+	return defaultCatalog.Message(locale, code, params)
 }
 
 // IsSuccess 判断是否为成功状态码
@@ -243,36 +245,130 @@ func IsServerError(code BusinessCode) bool {
 }
 
 // GetHTTPStatus 根据业务状态码获取对应的HTTP状态码
+// 优先使用注册表中声明的状态码，未注册的状态码按 成功 200、7000 以下 400、其余 500 处理
 func GetHTTPStatus(code BusinessCode) int {
+	if info, ok := defaultRegistry.Lookup(code); ok {
+		return info.HTTPStatus
+	}
 	switch {
 	case code == CodeSuccess:
 		return 200
-	case code == CodeInternalError:
-		return 500
-	case code >= CodeBadRequest && code <= CodeUnsupported:
+	case code > CodeSuccess && code < CodeDatabaseError:
 		return 400
-	case code == CodeConflict ||
-		code == CodeAuthAccountExists ||
-		code == CodeSessionInTransfer:
-		return 409
-	case code >= CodeAuthInvalidCredentials && code <= CodeAuthSessionExpired && code != CodeAuthAccountExists:
-		return 401
-	case code == CodeNotFound ||
-		code == CodeSessionNotFound ||
-		code == CodeMessageNotFound ||
-		code == CodeAgentNotFound:
-		return 404
-	case code == CodeForbidden ||
-		code == CodeAuthPermissionDenied ||
-		(code >= CodeAgentBusy && code <= CodeAgentPermissionDenied && code != CodeAgentNotFound):
-		return 403
-	case code >= CodeValidationFailed && code <= CodeQuotaExceeded:
-		return 400
-	case code >= CodeServiceBusy && code <= CodeAgentNoAvailable:
-		return 503
-	case code >= CodeDatabaseError:
-		return 500
 	default:
 		return 500
 	}
 }
+
+// builtinCode 使用 CodeMessage 中的消息构造内置状态码元信息
+func builtinCode(code BusinessCode, httpStatus int, retryable bool) CodeInfo {
+	return CodeInfo{Code: code, Message: CodeMessage[code], HTTPStatus: httpStatus, Retryable: retryable}
+}
+
+// 注册内置模块的状态码区间
+func init() {
+	MustRegisterModule(CodeModule{Name: "common", Min: 0, Max: 999, Codes: []CodeInfo{
+		builtinCode(CodeSuccess, 200, false),
+		builtinCode(CodeBadRequest, 400, false),
+		builtinCode(CodeUnauthorized, 401, false),
+		builtinCode(CodeForbidden, 403, false),
+		builtinCode(CodeNotFound, 404, false),
+		builtinCode(CodeConflict, 409, false),
+		builtinCode(CodeInternalError, 500, false),
+		builtinCode(CodeServiceBusy, 503, true),
+		builtinCode(CodeRateLimited, 429, true),
+		builtinCode(CodeTimeout, 504, true),
+		builtinCode(CodeUnsupported, 400, false),
+	}})
+
+	MustRegisterModule(CodeModule{Name: "auth", Min: 1000, Max: 1099, Codes: []CodeInfo{
+		builtinCode(CodeAuthInvalidCredentials, 401, false),
+		builtinCode(CodeAuthAccountNotFound, 401, false),
+		builtinCode(CodeAuthAccountExists, 409, false),
+		builtinCode(CodeAuthPasswordWeak, 400, false),
+		builtinCode(CodeAuthTokenInvalid, 401, false),
+		builtinCode(CodeAuthTokenExpired, 401, false),
+		builtinCode(CodeAuthPermissionDenied, 403, false),
+		builtinCode(CodeAuthLoginRequired, 401, false),
+		builtinCode(CodeAuthLogoutFailed, 500, true),
+		builtinCode(CodeAuthSessionExpired, 401, false),
+	}})
+
+	MustRegisterModule(CodeModule{Name: "session", Min: 2000, Max: 2099, Codes: []CodeInfo{
+		builtinCode(CodeSessionCreateFailed, 500, true),
+		builtinCode(CodeSessionNotFound, 404, false),
+		builtinCode(CodeSessionClosed, 409, false),
+		builtinCode(CodeSessionInactive, 409, false),
+		builtinCode(CodeSessionInTransfer, 409, true),
+		builtinCode(CodeSessionReconnectFailed, 500, true),
+		builtinCode(CodeSessionCloseFailed, 500, true),
+		builtinCode(CodeSessionTransferFailed, 500, true),
+		builtinCode(CodeSessionInvalidState, 409, false),
+		builtinCode(CodeSessionLimit, 429, true),
+	}})
+
+	MustRegisterModule(CodeModule{Name: "message", Min: 3000, Max: 3099, Codes: []CodeInfo{
+		builtinCode(CodeMessageSendFailed, 500, true),
+		builtinCode(CodeMessageNotFound, 404, false),
+		builtinCode(CodeMessageInvalidType, 400, false),
+		builtinCode(CodeMessageTooLarge, 413, false),
+		builtinCode(CodeMessageEmpty, 400, false),
+		builtinCode(CodeMessageReadFailed, 500, true),
+		builtinCode(CodeMessageDuplicate, 409, false),
+		builtinCode(CodeMessageRateLimited, 429, true),
+		builtinCode(CodeMessageFilterBlocked, 400, false),
+		builtinCode(CodeMessageHistoryFailed, 500, true),
+	}})
+
+	MustRegisterModule(CodeModule{Name: "agent", Min: 4000, Max: 4099, Codes: []CodeInfo{
+		builtinCode(CodeAgentNotFound, 404, false),
+		builtinCode(CodeAgentOffline, 503, true),
+		builtinCode(CodeAgentBusy, 503, true),
+		builtinCode(CodeAgentStatusInvalid, 400, false),
+		builtinCode(CodeAgentUpdateFailed, 500, true),
+		builtinCode(CodeAgentAssignFailed, 500, true),
+		builtinCode(CodeAgentNoAvailable, 503, true),
+		builtinCode(CodeAgentSessionLimit, 429, true),
+		builtinCode(CodeAgentPermissionDenied, 403, false),
+		builtinCode(CodeAgentAlreadyOnline, 409, false),
+	}})
+
+	MustRegisterModule(CodeModule{Name: "websocket", Min: 5000, Max: 5099, Codes: []CodeInfo{
+		builtinCode(CodeWSConnectFailed, 500, true),
+		builtinCode(CodeWSInvalidParams, 400, false),
+		builtinCode(CodeWSSessionRequired, 400, false),
+		builtinCode(CodeWSUserRequired, 400, false),
+		builtinCode(CodeWSTypeInvalid, 400, false),
+		builtinCode(CodeWSAlreadyConnected, 409, false),
+		builtinCode(CodeWSDisconnected, 409, true),
+		builtinCode(CodeWSMessageInvalid, 400, false),
+		builtinCode(CodeWSHeartbeatTimeout, 408, true),
+		builtinCode(CodeWSConnectionLimit, 429, true),
+	}})
+
+	MustRegisterModule(CodeModule{Name: "validation", Min: 6000, Max: 6099, Codes: []CodeInfo{
+		builtinCode(CodeValidationFailed, 400, false),
+		builtinCode(CodeParamRequired, 400, false),
+		builtinCode(CodeParamInvalid, 400, false),
+		builtinCode(CodeParamOutOfRange, 400, false),
+		builtinCode(CodeDataFormatError, 400, false),
+		builtinCode(CodeFileTypeNotSupported, 415, false),
+		builtinCode(CodeFileSizeExceeded, 413, false),
+		builtinCode(CodeOperationNotAllowed, 403, false),
+		builtinCode(CodeResourceInUse, 409, true),
+		builtinCode(CodeQuotaExceeded, 429, false),
+	}})
+
+	MustRegisterModule(CodeModule{Name: "external", Min: 7000, Max: 7099, Codes: []CodeInfo{
+		builtinCode(CodeDatabaseError, 500, false),
+		builtinCode(CodeRedisError, 503, true),
+		builtinCode(CodeCacheError, 500, true),
+		builtinCode(CodeNetworkError, 502, true),
+		builtinCode(CodeThirdPartyError, 502, true),
+		builtinCode(CodeConfigError, 500, false),
+		builtinCode(CodeStorageError, 503, true),
+		builtinCode(CodeQueueError, 503, true),
+		builtinCode(CodeLockError, 503, true),
+		builtinCode(CodeTransactionError, 500, true),
+	}})
+}
@@ -0,0 +1,143 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// mysqlErrDuplicateEntry MySQL 唯一键冲突错误码
+const mysqlErrDuplicateEntry = 1062
+
+// Error 业务错误，携带业务状态码、面向用户的消息、结构化详情和底层错误
+// 处理器通过 c.Error(err) 返回错误，由错误处理中间件统一转换为 BusinessResponse
+type Error struct {
+	Code    BusinessCode           // 业务状态码
+	Message string                 // 面向用户的消息，为空时使用消息目录中状态码对应的本地化消息
+	Params  map[string]interface{} // 消息模板参数，替换消息中的 {name} 占位符
+	Details interface{}            // 结构化详情，作为响应中的 data 返回
+	Cause   error                  // 底层错误，只用于日志和链路追踪，不返回给客户端
+}
+
+// NewError 创建业务错误
+func NewError(code BusinessCode) *Error {
+	return &Error{Code: code}
+}
+
+// Errorf 创建带自定义消息的业务错误
+func Errorf(code BusinessCode, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// WrapError 使用业务状态码包装底层错误，cause 为 nil 时返回 nil
+func WrapError(cause error, code BusinessCode) *Error {
+	if cause == nil {
+		return nil
+	}
+	return &Error{Code: code, Cause: cause}
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = GetMessage(e.Code)
+	}
+	msg = FormatMessage(msg, e.Params)
+	if e.Cause != nil {
+		return fmt.Sprintf("code=%d message=%s: %v", e.Code, msg, e.Cause)
+	}
+	return fmt.Sprintf("code=%d message=%s", e.Code, msg)
+}
+
+// Unwrap 返回底层错误，支持 errors.Is/As 穿透
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is 业务状态码相同即视为同一错误，使 errors.Is(err, NewError(CodeNotFound)) 成立
+func (e *Error) Is(target error) bool {
+	var t *Error
+	if !errors.As(target, &t) {
+		return false
+	}
+	return t.Code == e.Code
+}
+
+// WithMessage 返回设置了面向用户消息的副本
+func (e *Error) WithMessage(format string, args ...interface{}) *Error {
+	cp := *e
+	cp.Message = fmt.Sprintf(format, args...)
+	return &cp
+}
+
+// WithParams 返回设置了消息模板参数的副本
+func (e *Error) WithParams(params map[string]interface{}) *Error {
+	cp := *e
+	cp.Params = params
+	return &cp
+}
+
+// WithDetails 返回设置了结构化详情的副本
+func (e *Error) WithDetails(details interface{}) *Error {
+	cp := *e
+	cp.Details = details
+	return &cp
+}
+
+// WithCause 返回设置了底层错误的副本
+func (e *Error) WithCause(cause error) *Error {
+	cp := *e
+	cp.Cause = cause
+	return &cp
+}
+
+// HTTPStatus 错误对应的HTTP状态码
+func (e *Error) HTTPStatus() int {
+	return GetHTTPStatus(e.Code)
+}
+
+// FromError 将任意错误转换为业务错误
+// 错误链中已有 *Error 时直接返回；GORM 记录不存在映射为 CodeNotFound，唯一键冲突映射为 CodeConflict，
+// 上下文超时映射为 CodeTimeout，其余错误映射为 CodeInternalError
+func FromError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return &Error{Code: CodeNotFound, Cause: err}
+	case isDuplicateKey(err):
+		return &Error{Code: CodeConflict, Cause: err}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Code: CodeTimeout, Cause: err}
+	default:
+		return &Error{Code: CodeInternalError, Cause: err}
+	}
+}
+
+// CodeOf 返回错误对应的业务状态码，err 为 nil 时返回 CodeSuccess
+func CodeOf(err error) BusinessCode {
+	if err == nil {
+		return CodeSuccess
+	}
+	return FromError(err).Code
+}
+
+// isDuplicateKey 判断是否为唯一键冲突错误
+func isDuplicateKey(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
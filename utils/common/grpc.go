@@ -0,0 +1,199 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// GRPCErrorDomain gRPC 错误详情 ErrorInfo 的 domain，reason 为业务状态码
+const GRPCErrorDomain = "go-template"
+
+// grpcCodeOverrides 无法由HTTP状态码准确表达的 gRPC 状态码
+// 例如HTTP 409 默认映射为 Aborted，但"已存在"类冲突应为 AlreadyExists，状态不满足应为 FailedPrecondition
+var grpcCodeOverrides = map[BusinessCode]codes.Code{
+	CodeUnsupported:         codes.Unimplemented,
+	CodeAuthAccountExists:   codes.AlreadyExists,
+	CodeSessionClosed:       codes.FailedPrecondition,
+	CodeSessionInactive:     codes.FailedPrecondition,
+	CodeSessionInvalidState: codes.FailedPrecondition,
+	CodeMessageDuplicate:    codes.AlreadyExists,
+	CodeAgentStatusInvalid:  codes.FailedPrecondition,
+	CodeAgentAlreadyOnline:  codes.AlreadyExists,
+	CodeWSAlreadyConnected:  codes.AlreadyExists,
+	CodeOperationNotAllowed: codes.FailedPrecondition,
+}
+
+// GetGRPCCode 获取业务状态码对应的 gRPC 状态码，未单独指定时由HTTP状态码推导
+func GetGRPCCode(code BusinessCode) codes.Code {
+	if c, ok := grpcCodeOverrides[code]; ok {
+		return c
+	}
+
+	switch GetHTTPStatus(code) {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// GRPCStatus 转换为 gRPC 状态，使 status.FromError 可以识别业务错误，消息使用默认语言
+func (e *Error) GRPCStatus() *status.Status {
+	return e.LocaleGRPCStatus(DefaultLocale)
+}
+
+// LocaleGRPCStatus 转换为指定语言的 gRPC 状态
+// 详情中包含 ErrorInfo（reason 为业务状态码，metadata 含 code 和 retryable），
+// Details 不为空时追加 JSON 编码后的 structpb.Value；底层错误不返回给客户端
+func (e *Error) LocaleGRPCStatus(locale string) *status.Status {
+	code := GetGRPCCode(e.Code)
+	if code == codes.OK {
+		return status.New(codes.OK, "")
+	}
+
+	msg := GetLocaleMessage(locale, e.Code, e.Params)
+	if e.Message != "" {
+		msg = FormatMessage(e.Message, e.Params)
+	}
+
+	st := status.New(code, msg)
+	withInfo, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: strconv.Itoa(int(e.Code)),
+		Domain: GRPCErrorDomain,
+		Metadata: map[string]string{
+			"code":      strconv.Itoa(int(e.Code)),
+			"retryable": strconv.FormatBool(IsRetryable(e.Code)),
+		},
+	})
+	if err != nil {
+		return st
+	}
+	st = withInfo
+
+	if e.Details == nil {
+		return st
+	}
+	value, err := detailsValue(e.Details)
+	if err != nil {
+		return st
+	}
+	if withValue, err := st.WithDetails(value); err == nil {
+		st = withValue
+	}
+	return st
+}
+
+// ToGRPCStatus 将任意错误转换为指定语言的 gRPC 状态
+// 已是 gRPC 状态的错误原样返回，上下文取消映射为 Canceled，其余错误经 FromError 转换为业务错误
+func ToGRPCStatus(err error, locale string) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.FromContextError(err)
+	}
+
+	var e *Error
+	if !errors.As(err, &e) {
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+	}
+	return FromError(err).LocaleGRPCStatus(locale)
+}
+
+// FromGRPCError 将 gRPC 客户端调用返回的错误转换为业务错误
+// 服务端为本模板生成时从 ErrorInfo 中恢复业务状态码和详情，否则按 gRPC 状态码推导
+func FromGRPCError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return FromError(err)
+	}
+
+	e := &Error{Code: codeFromGRPC(st.Code()), Message: st.Message(), Cause: err}
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetDomain() != GRPCErrorDomain {
+				continue
+			}
+			if code, err := strconv.Atoi(d.GetReason()); err == nil {
+				e.Code = BusinessCode(code)
+			}
+		case *structpb.Value:
+			e.Details = d.AsInterface()
+		}
+	}
+	return e
+}
+
+// codeFromGRPC 由 gRPC 状态码推导业务状态码
+func codeFromGRPC(code codes.Code) BusinessCode {
+	switch code {
+	case codes.OK:
+		return CodeSuccess
+	case codes.InvalidArgument, codes.OutOfRange:
+		return CodeBadRequest
+	case codes.Unauthenticated:
+		return CodeUnauthorized
+	case codes.PermissionDenied:
+		return CodeForbidden
+	case codes.NotFound:
+		return CodeNotFound
+	case codes.AlreadyExists, codes.Aborted, codes.FailedPrecondition:
+		return CodeConflict
+	case codes.ResourceExhausted:
+		return CodeRateLimited
+	case codes.DeadlineExceeded, codes.Canceled:
+		return CodeTimeout
+	case codes.Unimplemented:
+		return CodeUnsupported
+	case codes.Unavailable:
+		return CodeServiceBusy
+	default:
+		return CodeInternalError
+	}
+}
+
+// detailsValue 将结构化详情编码为 structpb.Value
+func detailsValue(details interface{}) (*structpb.Value, error) {
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return structpb.NewValue(v)
+}
@@ -0,0 +1,258 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	// DefaultLocale 默认语言，对应内置的 CodeMessage
+	DefaultLocale = "zh-CN"
+	// LocaleQueryParam 指定语言的查询参数，优先级高于 Accept-Language
+	LocaleQueryParam = "lang"
+	// localeContextKey 语言在 gin.Context 中的缓存键
+	localeContextKey = "locale"
+)
+
+// Catalog 多语言消息目录，按 语言 -> 业务状态码 存储消息
+// 未找到对应语言的消息时依次回退到基础语言（如 en-US -> en）和内置的中文 CodeMessage
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[BusinessCode]string
+}
+
+// NewCatalog 创建消息目录
+func NewCatalog() *Catalog {
+	return &Catalog{messages: make(map[string]map[BusinessCode]string)}
+}
+
+var defaultCatalog = NewCatalog()
+
+// DefaultCatalog 返回 GetMessage 和 BusinessResponse 使用的全局消息目录
+func DefaultCatalog() *Catalog {
+	return defaultCatalog
+}
+
+// Add 添加指定语言的消息，已存在的状态码会被覆盖
+func (c *Catalog) Add(locale string, messages map[BusinessCode]string) {
+	locale = normalizeLocale(locale)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, ok := c.messages[locale]
+	if !ok {
+		m = make(map[BusinessCode]string, len(messages))
+		c.messages[locale] = m
+	}
+	for code, msg := range messages {
+		m[code] = msg
+	}
+}
+
+// LoadFile 从 TOML 或 JSON 文件加载消息，文件名（不含扩展名）即语言，如 en.toml、ja-JP.json
+// 文件内容以状态码为键：TOML 写作 103 = "Resource not found"，JSON 写作 {"103": "Resource not found"}
+func (c *Catalog) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	raw := make(map[string]string)
+	ext := strings.ToLower(filepath.Ext(path))
+	switch ext {
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	default:
+		return fmt.Errorf("i18n: unsupported catalog file %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("i18n: parse %s: %w", path, err)
+	}
+
+	messages := make(map[BusinessCode]string, len(raw))
+	for key, msg := range raw {
+		code, err := strconv.Atoi(key)
+		if err != nil {
+			return fmt.Errorf("i18n: invalid business code %q in %s", key, path)
+		}
+		messages[BusinessCode(code)] = msg
+	}
+
+	c.Add(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), messages)
+	return nil
+}
+
+// LoadDir 加载目录下所有 .toml 和 .json 消息文件
+func (c *Catalog) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".toml" && ext != ".json") {
+			continue
+		}
+		if err := c.LoadFile(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Locales 返回已加载的语言（包含默认语言）
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	locales := []string{DefaultLocale}
+	for locale := range c.messages {
+		if locale != DefaultLocale {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales[1:])
+	return locales
+}
+
+// Message 返回指定语言的消息，并使用 params 替换消息中的 {name} 占位符
+func (c *Catalog) Message(locale string, code BusinessCode, params map[string]interface{}) string {
+	return FormatMessage(c.lookup(locale, code), params)
+}
+
+// lookup 按 语言 -> 基础语言 -> 默认语言 -> 注册表/内置中文 的顺序查找消息
+func (c *Catalog) lookup(locale string, code BusinessCode) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, l := range []string{normalizeLocale(locale), baseLanguage(locale), DefaultLocale} {
+		if l == "" {
+			continue
+		}
+		if msg, ok := c.messages[l][code]; ok {
+			return msg
+		}
+	}
+	if info, ok := defaultRegistry.Lookup(code); ok && info.Message != "" {
+		return info.Message
+	}
+	if msg, ok := CodeMessage[code]; ok {
+		return msg
+	}
+	return "未知错误"
+}
+
+// Match 根据 Accept-Language 选择已加载的语言，按权重从高到低匹配，无匹配时返回默认语言
+func (c *Catalog) Match(acceptLanguage string) string {
+	type candidate struct {
+		locale string
+		q      float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{locale: tag, q: q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	for _, cand := range candidates {
+		if locale, ok := c.supported(cand.locale); ok {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+// supported 判断语言是否可用，返回目录中实际使用的语言标识
+func (c *Catalog) supported(locale string) (string, bool) {
+	locale = normalizeLocale(locale)
+	base := baseLanguage(locale)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if _, ok := c.messages[locale]; ok {
+		return locale, true
+	}
+	if _, ok := c.messages[base]; ok {
+		return base, true
+	}
+	if base == baseLanguage(DefaultLocale) {
+		return DefaultLocale, true
+	}
+	return "", false
+}
+
+// FormatMessage 使用 params 替换消息中的 {name} 占位符，未提供的占位符保持原样
+func FormatMessage(message string, params map[string]interface{}) string {
+	if len(params) == 0 || !strings.Contains(message, "{") {
+		return message
+	}
+
+	replacements := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(message)
+}
+
+// Locale 获取当前请求的语言：查询参数 lang 优先，其次为 Accept-Language，结果缓存在 gin.Context 中
+func Locale(c *gin.Context) string {
+	if locale := c.GetString(localeContextKey); locale != "" {
+		return locale
+	}
+
+	locale := DefaultLocale
+	if lang := c.Query(LocaleQueryParam); lang != "" {
+		if l, ok := defaultCatalog.supported(lang); ok {
+			locale = l
+		}
+	} else if accept := c.GetHeader("Accept-Language"); accept != "" {
+		locale = defaultCatalog.Match(accept)
+	}
+
+	c.Set(localeContextKey, locale)
+	return locale
+}
+
+// normalizeLocale 规范化语言标识，如 en_us -> en-US
+func normalizeLocale(locale string) string {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	lang, region, found := strings.Cut(locale, "-")
+	if !found {
+		return strings.ToLower(lang)
+	}
+	return strings.ToLower(lang) + "-" + strings.ToUpper(region)
+}
+
+// baseLanguage 返回基础语言，如 en-US -> en
+func baseLanguage(locale string) string {
+	lang, _, _ := strings.Cut(normalizeLocale(locale), "-")
+	return lang
+}
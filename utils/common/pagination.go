@@ -0,0 +1,413 @@
+package common
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// 分页查询参数名
+const (
+	PageParam   = "page"   // 页码，从1开始
+	SizeParam   = "size"   // 每页数量
+	CursorParam = "cursor" // 游标分页的游标
+	SortParam   = "sort"   // 排序字段，逗号分隔，"-" 前缀表示降序，如 -created_at,id
+)
+
+// maxFilterValues in/nin 过滤允许的最大取值数量
+const maxFilterValues = 100
+
+// PageOptions 分页选项，排序和过滤字段均需显式加入白名单
+type PageOptions struct {
+	DefaultSize  int               // 默认每页数量，默认 20
+	MaxSize      int               // 最大每页数量，默认 100
+	SortFields   map[string]string // 可排序字段：请求字段名 -> 数据库列名
+	DefaultSort  string            // 默认排序，如 "-created_at"
+	FilterFields map[string]string // 可过滤字段：请求字段名 -> 数据库列名
+	KeyField     string            // 唯一键列名，默认 "id"，总是作为最后的排序列以保证顺序稳定
+	Keyset       bool              // 使用游标（keyset）分页，代替 OFFSET 分页
+	SkipTotal    bool              // 不统计总数，用于大表
+}
+
+// PageResult 分页结果，作为 Response.Data 返回
+type PageResult[T any] struct {
+	Items      []T    `json:"items"`                 // 当前页数据
+	Total      int64  `json:"total"`                 // 满足过滤条件的总数，SkipTotal 时为 -1
+	NextCursor string `json:"next_cursor,omitempty"` // 下一页游标，游标分页且还有更多数据时返回
+}
+
+// Page 解析后的分页请求
+type Page struct {
+	Number  int // 页码，游标分页时固定为 1
+	Size    int // 每页数量
+	Cursor  string
+	sorts   []sortColumn
+	filters []clause.Expression
+	after   []json.RawMessage // 游标中记录的上一页最后一行的排序列取值
+	options *PageOptions
+}
+
+// sortColumn 排序列
+type sortColumn struct {
+	column string
+	desc   bool
+}
+
+// cursorPayload 游标内容，记录生成游标时的排序以防止不同排序间混用
+type cursorPayload struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// filterOperators 过滤操作符
+var filterOperators = map[string]func(column clause.Column, value string) clause.Expression{
+	"eq":  func(col clause.Column, v string) clause.Expression { return clause.Eq{Column: col, Value: v} },
+	"ne":  func(col clause.Column, v string) clause.Expression { return clause.Neq{Column: col, Value: v} },
+	"gt":  func(col clause.Column, v string) clause.Expression { return clause.Gt{Column: col, Value: v} },
+	"gte": func(col clause.Column, v string) clause.Expression { return clause.Gte{Column: col, Value: v} },
+	"lt":  func(col clause.Column, v string) clause.Expression { return clause.Lt{Column: col, Value: v} },
+	"lte": func(col clause.Column, v string) clause.Expression { return clause.Lte{Column: col, Value: v} },
+	"in":  func(col clause.Column, v string) clause.Expression { return clause.IN{Column: col, Values: splitValues(v)} },
+	"nin": func(col clause.Column, v string) clause.Expression {
+		return clause.Not(clause.IN{Column: col, Values: splitValues(v)})
+	},
+	"like": func(col clause.Column, v string) clause.Expression {
+		return clause.Like{Column: col, Value: "%" + escapeLike(v) + "%"}
+	},
+}
+
+// ParsePage 从查询参数解析分页、排序和过滤条件
+// 过滤条件格式为 字段=操作符:值，如 status=eq:1&created_at=gte:2024-01-01，省略操作符或前缀不是已知操作符时为 eq；
+// 支持的操作符：eq、ne、gt、gte、lt、lte、in、nin（值以逗号分隔）、like。
+// 未加入 FilterFields 白名单的查询参数视为不支持的过滤条件。
+// 参数不合法时返回携带 []FieldError 详情的 CodeParamInvalid 错误
+func ParsePage(c *gin.Context, options *PageOptions) (*Page, error) {
+	// options 通常是多个请求共享的包级变量，默认值只写入副本
+	opts := *options
+	options = &opts
+	if options.DefaultSize <= 0 {
+		options.DefaultSize = 20
+	}
+	if options.MaxSize <= 0 {
+		options.MaxSize = 100
+	}
+	if options.KeyField == "" {
+		options.KeyField = "id"
+	}
+
+	locale := Locale(c)
+	var details []FieldError
+	invalid := func(field, rule, param string) {
+		details = append(details, FieldError{Field: field, Rule: rule, Param: param, Message: ruleMessage(locale, field, rule, param)})
+	}
+
+	p := &Page{Number: 1, Size: options.DefaultSize, options: options}
+
+	if v := c.Query(PageParam); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			invalid(PageParam, "min", "1")
+		} else if !options.Keyset {
+			p.Number = n
+		}
+	}
+	if v := c.Query(SizeParam); v != "" {
+		n, err := strconv.Atoi(v)
+		switch {
+		case err != nil || n < 1:
+			invalid(SizeParam, "min", "1")
+		case n > options.MaxSize:
+			invalid(SizeParam, "max", strconv.Itoa(options.MaxSize))
+		default:
+			p.Size = n
+		}
+	}
+
+	// 排序
+	sort := c.DefaultQuery(SortParam, options.DefaultSort)
+	for _, name := range strings.Split(sort, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "-"), "+")
+		column, ok := options.SortFields[name]
+		if !ok {
+			invalid(SortParam, "sortable", name)
+			continue
+		}
+		p.sorts = append(p.sorts, sortColumn{column: column, desc: desc})
+	}
+	p.sorts = appendKeyColumn(p.sorts, options.KeyField)
+
+	// 过滤
+	query := c.Request.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		values := query[name]
+		if name == PageParam || name == SizeParam || name == CursorParam || name == SortParam || name == LocaleQueryParam {
+			continue
+		}
+		column, ok := options.FilterFields[name]
+		if !ok {
+			invalid(name, "filter", name)
+			continue
+		}
+		for _, value := range values {
+			// 前缀不是已知操作符时整个值按 eq 处理，值本身可以包含冒号（如时间、URN）
+			op, operand, _ := strings.Cut(value, ":")
+			build, ok := filterOperators[op]
+			if !ok {
+				op, operand, build = "eq", value, filterOperators["eq"]
+			}
+			if (op == "in" || op == "nin") && len(splitValues(operand)) > maxFilterValues {
+				invalid(name, "max.len", strconv.Itoa(maxFilterValues))
+				continue
+			}
+			p.filters = append(p.filters, build(clause.Column{Name: column}, operand))
+		}
+	}
+
+	// 游标
+	if cursor := c.Query(CursorParam); cursor != "" && options.Keyset {
+		values, err := decodeCursor(cursor, p.sortKey(), len(p.sorts))
+		if err != nil {
+			invalid(CursorParam, "cursor", "")
+		} else {
+			p.Cursor = cursor
+			p.after = values
+		}
+	}
+
+	if len(details) > 0 {
+		return nil, NewError(CodeParamInvalid).WithDetails(details)
+	}
+	return p, nil
+}
+
+// Offset 偏移量，游标分页时为 0
+func (p *Page) Offset() int {
+	if p.options.Keyset {
+		return 0
+	}
+	return (p.Number - 1) * p.Size
+}
+
+// FilterScope 过滤条件
+func (p *Page) FilterScope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(p.filters) == 0 {
+			return db
+		}
+		return db.Where(clause.And(p.filters...))
+	}
+}
+
+// SortScope 排序条件
+func (p *Page) SortScope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, s := range p.sorts {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: s.column}, Desc: s.desc})
+		}
+		return db
+	}
+}
+
+// Scope 完整的分页条件：过滤、排序、游标条件以及 LIMIT/OFFSET
+// 游标分页时多取一条用于判断是否还有下一页，Paginate 会自动处理
+func (p *Page) Scope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = p.SortScope()(p.FilterScope()(db))
+		if p.options.Keyset {
+			if cond := p.keysetCondition(db); cond != nil {
+				db = db.Where(cond)
+			}
+			return db.Limit(p.Size + 1)
+		}
+		return db.Offset(p.Offset()).Limit(p.Size)
+	}
+}
+
+// Paginate 执行分页查询，返回当前页数据、总数以及下一页游标
+func Paginate[T any](db *gorm.DB, page *Page) (*PageResult[T], error) {
+	result := &PageResult[T]{Items: make([]T, 0, page.Size), Total: -1}
+
+	if !page.options.SkipTotal {
+		if err := db.Session(&gorm.Session{}).Model(new(T)).Scopes(page.FilterScope()).Count(&result.Total).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.Session(&gorm.Session{}).Model(new(T)).Scopes(page.Scope()).Find(&result.Items).Error; err != nil {
+		return nil, err
+	}
+
+	if page.options.Keyset && len(result.Items) > page.Size {
+		result.Items = result.Items[:page.Size]
+		cursor, err := page.encodeCursor(db, &result.Items[page.Size-1])
+		if err != nil {
+			return nil, err
+		}
+		result.NextCursor = cursor
+	}
+	return result, nil
+}
+
+// keysetCondition 构造游标条件：(a > va) OR (a = va AND b > vb) OR ...，降序列使用 <
+func (p *Page) keysetCondition(db *gorm.DB) clause.Expression {
+	if len(p.after) == 0 {
+		return nil
+	}
+
+	values, err := p.cursorValues(db)
+	if err != nil {
+		_ = db.AddError(err)
+		return nil
+	}
+
+	or := make([]clause.Expression, 0, len(p.sorts))
+	for i, s := range p.sorts {
+		and := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			and = append(and, clause.Eq{Column: clause.Column{Name: p.sorts[j].column}, Value: values[j]})
+		}
+		col := clause.Column{Name: s.column}
+		if s.desc {
+			and = append(and, clause.Lt{Column: col, Value: values[i]})
+		} else {
+			and = append(and, clause.Gt{Column: col, Value: values[i]})
+		}
+		or = append(or, clause.And(and...))
+	}
+	return clause.Or(or...)
+}
+
+// cursorValues 按模型字段类型还原游标中的取值，无法确定类型时按JSON原始类型处理
+func (p *Page) cursorValues(db *gorm.DB) ([]interface{}, error) {
+	var sch *schema.Schema
+	if db.Statement.Model != nil && db.Statement.Parse(db.Statement.Model) == nil {
+		sch = db.Statement.Schema
+	}
+
+	values := make([]interface{}, len(p.after))
+	for i, raw := range p.after {
+		if sch != nil {
+			if field := sch.LookUpField(p.sorts[i].column); field != nil {
+				ptr := reflect.New(field.FieldType)
+				if err := json.Unmarshal(raw, ptr.Interface()); err != nil {
+					return nil, fmt.Errorf("pagination: invalid cursor value for %s: %w", p.sorts[i].column, err)
+				}
+				values[i] = ptr.Elem().Interface()
+				continue
+			}
+		}
+
+		var v interface{}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("pagination: invalid cursor value for %s: %w", p.sorts[i].column, err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// encodeCursor 使用最后一行的排序列取值生成游标
+func (p *Page) encodeCursor(db *gorm.DB, item interface{}) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(item); err != nil {
+		return "", err
+	}
+
+	rv := reflect.ValueOf(item).Elem()
+	payload := cursorPayload{Sort: p.sortKey(), Values: make([]json.RawMessage, 0, len(p.sorts))}
+	for _, s := range p.sorts {
+		field := stmt.Schema.LookUpField(s.column)
+		if field == nil {
+			return "", fmt.Errorf("pagination: sort column %q not found in %s", s.column, stmt.Schema.Name)
+		}
+		value, _ := field.ValueOf(db.Statement.Context, rv)
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		payload.Values = append(payload.Values, raw)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// sortKey 排序的规范化表示，用于校验游标
+func (p *Page) sortKey() string {
+	parts := make([]string, 0, len(p.sorts))
+	for _, s := range p.sorts {
+		if s.desc {
+			parts = append(parts, "-"+s.column)
+		} else {
+			parts = append(parts, s.column)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// decodeCursor 解析游标，排序与游标生成时不一致时返回错误
+func decodeCursor(cursor, sortKey string, columns int) ([]json.RawMessage, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Sort != sortKey || len(payload.Values) != columns {
+		return nil, fmt.Errorf("pagination: cursor does not match sort %q", sortKey)
+	}
+	return payload.Values, nil
+}
+
+// appendKeyColumn 在排序末尾追加唯一键列，方向与最后一个排序列一致
+func appendKeyColumn(sorts []sortColumn, key string) []sortColumn {
+	desc := false
+	for _, s := range sorts {
+		if s.column == key {
+			return sorts
+		}
+		desc = s.desc
+	}
+	return append(sorts, sortColumn{column: key, desc: desc})
+}
+
+// splitValues 拆分逗号分隔的取值
+func splitValues(value string) []interface{} {
+	parts := strings.Split(value, ",")
+	values := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+// escapeLike 转义 LIKE 通配符
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
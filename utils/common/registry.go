@@ -0,0 +1,162 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CodeInfo 业务状态码元信息
+type CodeInfo struct {
+	Code       BusinessCode `json:"code"`        // 业务状态码
+	Message    string       `json:"message"`     // 默认（中文）消息，其他语言由消息目录提供
+	HTTPStatus int          `json:"http_status"` // 对应的HTTP状态码
+	Retryable  bool         `json:"retryable"`   // 客户端是否可以重试
+	Module     string       `json:"module"`      // 所属模块，注册时自动填充
+}
+
+// CodeModule 模块的业务状态码声明，模块只能在自己的区间 [Min, Max] 内定义状态码
+type CodeModule struct {
+	Name  string       // 模块名称
+	Min   BusinessCode // 区间下限（包含）
+	Max   BusinessCode // 区间上限（包含）
+	Codes []CodeInfo   // 模块定义的状态码
+}
+
+// CodeRegistry 业务状态码注册表
+// 各模块在启动时注册自己的状态码区间，区间重叠、状态码重复或越界时注册失败
+type CodeRegistry struct {
+	mu      sync.RWMutex
+	modules []CodeModule
+	codes   map[BusinessCode]CodeInfo
+}
+
+// NewCodeRegistry 创建业务状态码注册表
+func NewCodeRegistry() *CodeRegistry {
+	return &CodeRegistry{codes: make(map[BusinessCode]CodeInfo)}
+}
+
+var defaultRegistry = NewCodeRegistry()
+
+// DefaultRegistry 返回全局业务状态码注册表，GetHTTPStatus 和 GetMessage 基于该注册表
+func DefaultRegistry() *CodeRegistry {
+	return defaultRegistry
+}
+
+// RegisterModule 向全局注册表注册模块状态码
+func RegisterModule(module CodeModule) error {
+	return defaultRegistry.Register(module)
+}
+
+// MustRegisterModule 向全局注册表注册模块状态码，失败时 panic，用于包初始化或应用启动阶段
+func MustRegisterModule(module CodeModule) {
+	if err := defaultRegistry.Register(module); err != nil {
+		panic(err)
+	}
+}
+
+// Register 注册模块状态码，所有检查通过后才会生效
+func (r *CodeRegistry) Register(module CodeModule) error {
+	if module.Name == "" {
+		return fmt.Errorf("code registry: module name is empty")
+	}
+	if module.Min < 0 || module.Min > module.Max {
+		return fmt.Errorf("code registry: module %q has invalid range [%d, %d]", module.Name, module.Min, module.Max)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, m := range r.modules {
+		if m.Name == module.Name {
+			return fmt.Errorf("code registry: module %q already registered", module.Name)
+		}
+		if module.Min <= m.Max && m.Min <= module.Max {
+			return fmt.Errorf("code registry: module %q range [%d, %d] overlaps module %q range [%d, %d]",
+				module.Name, module.Min, module.Max, m.Name, m.Min, m.Max)
+		}
+	}
+
+	seen := make(map[BusinessCode]bool, len(module.Codes))
+	for _, info := range module.Codes {
+		if info.Code < module.Min || info.Code > module.Max {
+			return fmt.Errorf("code registry: code %d of module %q is out of range [%d, %d]", info.Code, module.Name, module.Min, module.Max)
+		}
+		if seen[info.Code] {
+			return fmt.Errorf("code registry: code %d registered twice by module %q", info.Code, module.Name)
+		}
+		if info.HTTPStatus < 100 || info.HTTPStatus > 599 {
+			return fmt.Errorf("code registry: code %d of module %q has invalid http status %d", info.Code, module.Name, info.HTTPStatus)
+		}
+		seen[info.Code] = true
+	}
+
+	for _, info := range module.Codes {
+		info.Module = module.Name
+		r.codes[info.Code] = info
+	}
+	r.modules = append(r.modules, CodeModule{Name: module.Name, Min: module.Min, Max: module.Max})
+	return nil
+}
+
+// Lookup 查询状态码元信息
+func (r *CodeRegistry) Lookup(code BusinessCode) (CodeInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	info, ok := r.codes[code]
+	return info, ok
+}
+
+// Codes 返回按状态码排序的完整状态码表
+func (r *CodeRegistry) Codes() []CodeInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	codes := make([]CodeInfo, 0, len(r.codes))
+	for _, info := range r.codes {
+		codes = append(codes, info)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i].Code < codes[j].Code
+	})
+	return codes
+}
+
+// Modules 返回按区间排序的已注册模块（不含状态码列表）
+func (r *CodeRegistry) Modules() []CodeModule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	modules := make([]CodeModule, len(r.modules))
+	copy(modules, r.modules)
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Min < modules[j].Min
+	})
+	return modules
+}
+
+// IsRetryable 判断状态码对应的错误是否可以重试
+func IsRetryable(code BusinessCode) bool {
+	info, ok := defaultRegistry.Lookup(code)
+	return ok && info.Retryable
+}
+
+// CodeTableHandler 输出全局注册表中的状态码表，消息按请求语言本地化
+func CodeTableHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := Locale(c)
+		codes := defaultRegistry.Codes()
+		for i := range codes {
+			codes[i].Message = GetLocaleMessage(locale, codes[i].Code, nil)
+		}
+		c.JSON(http.StatusOK, SuccessResponse{
+			Code:    CodeSuccess,
+			Message: GetLocaleMessage(locale, CodeSuccess, nil),
+			Data:    codes,
+		})
+	}
+}
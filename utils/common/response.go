@@ -64,7 +64,13 @@ func SuccessResponseFunc(c *gin.Context, message string, data interface{}) {
 // @Param code body BusinessCode true "业务状态码"
 // @Param data body interface{} false "响应数据"
 func BusinessResponse(c *gin.Context, code BusinessCode, data interface{}) {
-	message := GetMessage(code)
+	BusinessResponseWithParams(c, code, nil, data)
+}
+
+// BusinessResponseWithParams 带消息模板参数的业务状态码响应函数
+// 消息按请求语言（查询参数 lang 或 Accept-Language）从消息目录中选取，并替换其中的 {name} 占位符
+func BusinessResponseWithParams(c *gin.Context, code BusinessCode, params map[string]interface{}, data interface{}) {
+	message := GetLocaleMessage(Locale(c), code, params)
 	httpStatus := GetHTTPStatus(code)
 
 	if IsSuccess(code) {
@@ -93,7 +99,7 @@ func BusinessResponse(c *gin.Context, code BusinessCode, data interface{}) {
 // @Param data body interface{} false "响应数据"
 func BusinessResponseWithMessage(c *gin.Context, code BusinessCode, message string, data interface{}) {
 	if message == "" {
-		message = GetMessage(code)
+		message = GetLocaleMessage(Locale(c), code, nil)
 	}
 	httpStatus := GetHTTPStatus(code)
 
@@ -0,0 +1,415 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError 单个字段的校验错误，作为 CodeValidationFailed 响应的 data 返回
+type FieldError struct {
+	Field   string `json:"field"`   // 字段路径，使用 json 字段名，如 items[0].name
+	Rule    string `json:"rule"`    // 校验规则，如 required、min
+	Param   string `json:"param"`   // 规则参数，如 min=3 中的 3
+	Message string `json:"message"` // 本地化的错误消息
+}
+
+var (
+	validate     *validator.Validate
+	validateOnce sync.Once
+
+	phoneRegexp    = regexp.MustCompile(`^1[3-9]\d{9}$`)
+	usernameRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+)
+
+// Validator 返回全局校验器，使用 validate 标签，并已注册 phone、idcard、username 自定义规则
+func Validator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New(validator.WithRequiredStructEnabled())
+		validate.SetTagName("validate")
+		validate.RegisterTagNameFunc(fieldName)
+
+		_ = validate.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+			return phoneRegexp.MatchString(fl.Field().String())
+		})
+		_ = validate.RegisterValidation("idcard", func(fl validator.FieldLevel) bool {
+			return IsValidIDCard(fl.Field().String())
+		})
+		_ = validate.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+			return usernameRegexp.MatchString(fl.Field().String())
+		})
+	})
+	return validate
+}
+
+// RegisterValidation 注册自定义校验规则及其各语言的错误消息，消息中可使用 {field}、{param} 占位符
+func RegisterValidation(tag string, fn validator.Func, messages map[string]string) error {
+	if err := Validator().RegisterValidation(tag, fn); err != nil {
+		return err
+	}
+	for locale, message := range messages {
+		RegisterValidationMessages(locale, map[string]string{tag: message})
+	}
+	return nil
+}
+
+// Bind 绑定请求参数并校验
+// 按结构体标签依次绑定请求头（header）、查询参数（form）、请求体（JSON 或表单）和路径参数（uri），
+// 然后使用 validate 标签校验。绑定失败返回 CodeDataFormatError，校验失败返回携带 []FieldError 详情的 CodeValidationFailed
+func Bind(c *gin.Context, obj interface{}) error {
+	tags := structTags(reflect.TypeOf(obj))
+
+	if tags["header"] {
+		if err := c.ShouldBindHeader(obj); err != nil {
+			return bindError(c, err)
+		}
+	}
+	if tags["form"] {
+		if err := c.ShouldBindQuery(obj); err != nil {
+			return bindError(c, err)
+		}
+	}
+	if hasBody(c.Request) {
+		var err error
+		switch c.ContentType() {
+		case binding.MIMEPOSTForm, binding.MIMEMultipartPOSTForm:
+			err = c.ShouldBindWith(obj, binding.Form)
+		default:
+			err = c.ShouldBindJSON(obj)
+		}
+		if err != nil {
+			return bindError(c, err)
+		}
+	}
+	if tags["uri"] && len(c.Params) > 0 {
+		if err := c.ShouldBindUri(obj); err != nil {
+			return bindError(c, err)
+		}
+	}
+
+	return Validate(c, obj)
+}
+
+// BindJSON 绑定JSON请求体并校验
+func BindJSON(c *gin.Context, obj interface{}) error {
+	return bindWith(c, obj, binding.JSON)
+}
+
+// BindQuery 绑定查询参数并校验
+func BindQuery(c *gin.Context, obj interface{}) error {
+	return bindWith(c, obj, binding.Query)
+}
+
+// BindURI 绑定路径参数并校验
+func BindURI(c *gin.Context, obj interface{}) error {
+	if err := c.ShouldBindUri(obj); err != nil {
+		return bindError(c, err)
+	}
+	return Validate(c, obj)
+}
+
+// BindHeader 绑定请求头并校验
+func BindHeader(c *gin.Context, obj interface{}) error {
+	return bindWith(c, obj, binding.Header)
+}
+
+// Validate 使用 validate 标签校验结构体，错误消息按请求语言本地化
+func Validate(c *gin.Context, obj interface{}) error {
+	err := Validator().Struct(obj)
+	if err == nil {
+		return nil
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return WrapError(err, CodeValidationFailed)
+	}
+	return &Error{
+		Code:    CodeValidationFailed,
+		Details: ValidationDetails(Locale(c), verrs),
+		Cause:   err,
+	}
+}
+
+// ValidationDetails 将校验错误转换为指定语言的 FieldError 列表
+func ValidationDetails(locale string, verrs validator.ValidationErrors) []FieldError {
+	details := make([]FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		field := fieldPath(fe.Namespace())
+		details = append(details, FieldError{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: validationMessage(locale, fe, field),
+		})
+	}
+	return details
+}
+
+// IsValidIDCard 校验18位居民身份证号码（出生日期与校验位）
+func IsValidIDCard(id string) bool {
+	if len(id) != 18 {
+		return false
+	}
+
+	weights := [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	sum := 0
+	for i := 0; i < 17; i++ {
+		if id[i] < '0' || id[i] > '9' {
+			return false
+		}
+		sum += int(id[i]-'0') * weights[i]
+	}
+	if _, err := time.Parse("20060102", id[6:14]); err != nil {
+		return false
+	}
+
+	check := "10X98765432"[sum%11]
+	last := id[17]
+	if last == 'x' {
+		last = 'X'
+	}
+	return last == check
+}
+
+// bindWith 使用指定绑定器绑定并校验
+func bindWith(c *gin.Context, obj interface{}, b binding.Binding) error {
+	if err := c.ShouldBindWith(obj, b); err != nil {
+		return bindError(c, err)
+	}
+	return Validate(c, obj)
+}
+
+// bindError 转换绑定阶段的错误，gin 使用 binding 标签校验失败时同样返回字段错误列表
+func bindError(c *gin.Context, err error) error {
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		return &Error{
+			Code:    CodeValidationFailed,
+			Details: ValidationDetails(Locale(c), verrs),
+			Cause:   err,
+		}
+	}
+	return WrapError(err, CodeDataFormatError)
+}
+
+// hasBody 判断请求是否携带请求体
+func hasBody(r *http.Request) bool {
+	if r.Body == nil || r.Body == http.NoBody {
+		return false
+	}
+	return r.ContentLength != 0 && r.Method != http.MethodGet && r.Method != http.MethodHead
+}
+
+// fieldName 使用 json 标签作为字段名，没有 json 标签时依次使用 form、uri、header 标签
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri", "header"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// fieldPath 去掉命名空间中的结构体名称，如 CreateUserRequest.items[0].name -> items[0].name
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+var structTagCache sync.Map // map[reflect.Type]map[string]bool
+
+// structTags 返回结构体（含嵌套结构体）中出现过的绑定标签
+func structTags(t reflect.Type) map[string]bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if cached, ok := structTagCache.Load(t); ok {
+		return cached.(map[string]bool)
+	}
+
+	tags := make(map[string]bool)
+	collectStructTags(t, tags, make(map[reflect.Type]bool))
+	structTagCache.Store(t, tags)
+	return tags
+}
+
+func collectStructTags(t reflect.Type, tags map[string]bool, visited map[reflect.Type]bool) {
+	if t.Kind() != reflect.Struct || visited[t] {
+		return
+	}
+	visited[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		for _, tag := range []string{"header", "form", "uri"} {
+			if _, ok := field.Tag.Lookup(tag); ok {
+				tags[tag] = true
+			}
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) {
+			collectStructTags(ft, tags, visited)
+		}
+	}
+}
+
+// validationMessages 各语言的校验规则消息，键为规则名，长度类规则针对字符串/切片时使用 "规则.len" 键
+var (
+	validationMessagesMu sync.RWMutex
+	validationMessages   = map[string]map[string]string{
+		"zh-CN": {
+			"required": "{field}不能为空",
+			"min":      "{field}不能小于{param}",
+			"min.len":  "{field}长度不能小于{param}",
+			"max":      "{field}不能大于{param}",
+			"max.len":  "{field}长度不能超过{param}",
+			"len":      "{field}必须等于{param}",
+			"len.len":  "{field}长度必须为{param}",
+			"eq":       "{field}必须等于{param}",
+			"ne":       "{field}不能等于{param}",
+			"gt":       "{field}必须大于{param}",
+			"gte":      "{field}必须大于或等于{param}",
+			"lt":       "{field}必须小于{param}",
+			"lte":      "{field}必须小于或等于{param}",
+			"oneof":    "{field}必须是[{param}]中的一个",
+			"email":    "{field}必须是有效的邮箱地址",
+			"url":      "{field}必须是有效的URL",
+			"numeric":  "{field}必须是数字",
+			"datetime": "{field}必须符合时间格式{param}",
+			"phone":    "{field}必须是有效的手机号码",
+			"idcard":   "{field}必须是有效的身份证号码",
+			"username": "{field}只能包含字母、数字和下划线，且必须以字母开头",
+			"sortable": "不支持按{param}排序",
+			"filter":   "不支持按{param}过滤",
+			"cursor":   "分页游标无效",
+			"default":  "{field}校验失败({rule})",
+		},
+		"en": {
+			"required": "{field} is required",
+			"min":      "{field} must be at least {param}",
+			"min.len":  "{field} must be at least {param} characters long",
+			"max":      "{field} must be at most {param}",
+			"max.len":  "{field} must be at most {param} characters long",
+			"len":      "{field} must be equal to {param}",
+			"len.len":  "{field} must be exactly {param} characters long",
+			"eq":       "{field} must be equal to {param}",
+			"ne":       "{field} must not be equal to {param}",
+			"gt":       "{field} must be greater than {param}",
+			"gte":      "{field} must be greater than or equal to {param}",
+			"lt":       "{field} must be less than {param}",
+			"lte":      "{field} must be less than or equal to {param}",
+			"oneof":    "{field} must be one of [{param}]",
+			"email":    "{field} must be a valid email address",
+			"url":      "{field} must be a valid URL",
+			"numeric":  "{field} must be numeric",
+			"datetime": "{field} must match the time format {param}",
+			"phone":    "{field} must be a valid mobile phone number",
+			"idcard":   "{field} must be a valid ID card number",
+			"username": "{field} may only contain letters, digits and underscores and must start with a letter",
+			"sortable": "sorting by {param} is not supported",
+			"filter":   "filtering by {param} is not supported",
+			"cursor":   "invalid pagination cursor",
+			"default":  "{field} failed on the {rule} rule",
+		},
+		"ja": {
+			"required": "{field}は必須です",
+			"min":      "{field}は{param}以上で入力してください",
+			"min.len":  "{field}は{param}文字以上で入力してください",
+			"max":      "{field}は{param}以下で入力してください",
+			"max.len":  "{field}は{param}文字以下で入力してください",
+			"oneof":    "{field}は[{param}]のいずれかを指定してください",
+			"email":    "{field}は有効なメールアドレスを入力してください",
+			"phone":    "{field}は有効な携帯電話番号を入力してください",
+			"idcard":   "{field}は有効な身分証番号を入力してください",
+			"username": "{field}は英字で始まり、英数字とアンダースコアのみ使用できます",
+			"sortable": "{param}での並べ替えはサポートされていません",
+			"filter":   "{param}での絞り込みはサポートされていません",
+			"cursor":   "ページングカーソルが無効です",
+			"default":  "{field}の検証に失敗しました({rule})",
+		},
+	}
+)
+
+// 内置校验消息的语言同样作为可选语言，即使未加载对应的状态码消息文件
+func init() {
+	for locale := range validationMessages {
+		defaultCatalog.Add(locale, nil)
+	}
+}
+
+// RegisterValidationMessages 注册或覆盖指定语言的校验规则消息
+func RegisterValidationMessages(locale string, messages map[string]string) {
+	locale = normalizeLocale(locale)
+	if baseLanguage(locale) == baseLanguage(DefaultLocale) {
+		locale = DefaultLocale
+	}
+	defaultCatalog.Add(locale, nil)
+
+	validationMessagesMu.Lock()
+	defer validationMessagesMu.Unlock()
+
+	m, ok := validationMessages[locale]
+	if !ok {
+		m = make(map[string]string, len(messages))
+		validationMessages[locale] = m
+	}
+	for rule, message := range messages {
+		m[rule] = message
+	}
+}
+
+// validationMessage 返回校验错误的本地化消息
+func validationMessage(locale string, fe validator.FieldError, field string) string {
+	keys := []string{fe.Tag()}
+	switch fe.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		keys = []string{fe.Tag() + ".len", fe.Tag()}
+	}
+	return ruleMessage(locale, field, fe.Tag(), fe.Param(), keys...)
+}
+
+// ruleMessage 按 语言 -> 基础语言 -> 默认语言 的顺序查找规则消息，均未定义时使用各语言的默认消息
+func ruleMessage(locale, field, rule, param string, keys ...string) string {
+	if len(keys) == 0 {
+		keys = []string{rule}
+	}
+	locales := []string{normalizeLocale(locale), baseLanguage(locale), DefaultLocale}
+	params := map[string]interface{}{"field": field, "param": param, "rule": rule}
+
+	validationMessagesMu.RLock()
+	defer validationMessagesMu.RUnlock()
+
+	for _, l := range locales {
+		for _, key := range keys {
+			if message, ok := validationMessages[l][key]; ok {
+				return FormatMessage(message, params)
+			}
+		}
+	}
+	for _, l := range locales {
+		if message, ok := validationMessages[l]["default"]; ok {
+			return FormatMessage(message, params)
+		}
+	}
+	return fmt.Sprintf("%s failed on the %s rule", field, rule)
+}